@@ -0,0 +1,291 @@
+package protokit
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Clone returns a deep copy of the file's descriptor tree, including the underlying descriptor protos, comments and
+// option maps. Parent and type references within the file are rewired to the copies, while references to other files
+// (dependencies, imports and types defined elsewhere) and the `protoreflect` descriptors are shared with the original.
+// Cloning a nil file returns `nil`.
+func (f *PKFileDescriptor) Clone() *PKFileDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	file := *f
+	file.desc = proto.Clone(f.desc).(*descriptorpb.FileDescriptorProto)
+
+	c := &cloner{
+		file:     &file,
+		protos:   make(map[proto.Message]proto.Message),
+		messages: make(map[*PKDescriptor]*PKDescriptor),
+		enums:    make(map[*PKEnumDescriptor]*PKEnumDescriptor),
+		methods:  make(map[*PKMethodDescriptor]*PKMethodDescriptor),
+	}
+	mapProtos(f.desc.ProtoReflect(), file.desc.ProtoReflect(), c.protos)
+
+	file.locations = parseLocations(file.desc)
+	file.comments = make(Comments, len(f.comments))
+	for path, comment := range f.comments {
+		file.comments[path] = cloneComment(comment)
+	}
+
+	file.PackageComments = cloneComment(f.PackageComments)
+	file.SyntaxComments = cloneComment(f.SyntaxComments)
+	file.OptionExtensions = cloneOptionExtensions(f.OptionExtensions)
+	file.Dependencies = append([]*PKFileDescriptor(nil), f.Dependencies...)
+	file.PublicDependencies = append([]*PKFileDescriptor(nil), f.PublicDependencies...)
+	file.WeakDependencies = append([]*PKFileDescriptor(nil), f.WeakDependencies...)
+
+	if f.Imports != nil {
+		file.Imports = make([]*PKImportedDescriptor, len(f.Imports))
+		for i, imp := range f.Imports {
+			file.Imports[i] = &PKImportedDescriptor{imp.common}
+		}
+	}
+
+	file.Enums = c.cloneEnums(f.Enums, nil)
+	file.Extensions = c.cloneExtensions(f.Extensions, nil)
+	file.Messages = c.cloneMessages(f.Messages, nil)
+	file.Services = c.cloneServices(f.Services)
+	c.rewire()
+
+	return &file
+}
+
+type cloner struct {
+	file     *PKFileDescriptor
+	protos   map[proto.Message]proto.Message
+	messages map[*PKDescriptor]*PKDescriptor
+	enums    map[*PKEnumDescriptor]*PKEnumDescriptor
+	methods  map[*PKMethodDescriptor]*PKMethodDescriptor
+
+	fields     []*PKFieldDescriptor
+	extensions []*PKExtensionDescriptor
+}
+
+// mapProtos records which message of the cloned proto corresponds to each message of the original one
+func mapProtos(orig, clone protoreflect.Message, protos map[proto.Message]proto.Message) {
+	protos[orig.Interface()] = clone.Interface()
+
+	orig.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.Message() == nil || fd.IsMap():
+		case fd.IsList():
+			origList, cloneList := v.List(), clone.Get(fd).List()
+			for i := 0; i < origList.Len(); i++ {
+				mapProtos(origList.Get(i).Message(), cloneList.Get(i).Message(), protos)
+			}
+		default:
+			mapProtos(v.Message(), clone.Get(fd).Message(), protos)
+		}
+
+		return true
+	})
+}
+
+func cloneComment(c *Comment) *Comment {
+	if c == nil {
+		return nil
+	}
+
+	return &Comment{
+		Leading:  c.Leading,
+		Trailing: c.Trailing,
+		Detached: append(make([]string, 0, len(c.Detached)), c.Detached...),
+	}
+}
+
+func cloneOptionExtensions(opts map[string]interface{}) map[string]interface{} {
+	if opts == nil {
+		return nil
+	}
+
+	clone := make(map[string]interface{}, len(opts))
+	for k, v := range opts {
+		clone[k] = v
+	}
+
+	return clone
+}
+
+func (c *cloner) cloneCommon(orig common) common {
+	orig.file = c.file
+	orig.OptionExtensions = cloneOptionExtensions(orig.OptionExtensions)
+	return orig
+}
+
+func (c *cloner) cloneEnums(orig []*PKEnumDescriptor, parent *PKDescriptor) []*PKEnumDescriptor {
+	if orig == nil {
+		return nil
+	}
+
+	enums := make([]*PKEnumDescriptor, len(orig))
+	for i, e := range orig {
+		enum := *e
+		enum.common = c.cloneCommon(e.common)
+		enum.desc = c.protos[e.desc].(*descriptorpb.EnumDescriptorProto)
+		enum.Comments = cloneComment(e.Comments)
+		enum.Parent = parent
+		c.enums[e] = &enum
+
+		if e.Values != nil {
+			enum.Values = make([]*PKEnumValueDescriptor, len(e.Values))
+			for j, v := range e.Values {
+				value := *v
+				value.common = c.cloneCommon(v.common)
+				value.desc = c.protos[v.desc].(*descriptorpb.EnumValueDescriptorProto)
+				value.Comments = cloneComment(v.Comments)
+				value.Enum = &enum
+				enum.Values[j] = &value
+			}
+		}
+
+		enums[i] = &enum
+	}
+
+	return enums
+}
+
+func (c *cloner) cloneExtensions(orig []*PKExtensionDescriptor, parent *PKDescriptor) []*PKExtensionDescriptor {
+	if orig == nil {
+		return nil
+	}
+
+	exts := make([]*PKExtensionDescriptor, len(orig))
+	for i, e := range orig {
+		ext := *e
+		ext.common = c.cloneCommon(e.common)
+		ext.desc = c.protos[e.desc].(*descriptorpb.FieldDescriptorProto)
+		ext.Comments = cloneComment(e.Comments)
+		ext.Parent = parent
+		c.extensions = append(c.extensions, &ext)
+		exts[i] = &ext
+	}
+
+	return exts
+}
+
+func (c *cloner) cloneMessages(orig []*PKDescriptor, parent *PKDescriptor) []*PKDescriptor {
+	if orig == nil {
+		return nil
+	}
+
+	msgs := make([]*PKDescriptor, len(orig))
+	for i, m := range orig {
+		// map entries are shared between Messages and MapEntries when parsed with IncludeMapEntries
+		if clone, ok := c.messages[m]; ok {
+			msgs[i] = clone
+			continue
+		}
+
+		msg := *m
+		msg.common = c.cloneCommon(m.common)
+		msg.desc = c.protos[m.desc].(*descriptorpb.DescriptorProto)
+		msg.Comments = cloneComment(m.Comments)
+		msg.Parent = parent
+		c.messages[m] = &msg
+
+		msg.Enums = c.cloneEnums(m.Enums, &msg)
+		msg.Extensions = c.cloneExtensions(m.Extensions, &msg)
+		msg.MapEntries = c.cloneMessages(m.MapEntries, &msg)
+		msg.Messages = c.cloneMessages(m.Messages, &msg)
+
+		if m.Fields != nil {
+			msg.Fields = make([]*PKFieldDescriptor, len(m.Fields))
+			for j, f := range m.Fields {
+				field := *f
+				field.common = c.cloneCommon(f.common)
+				field.desc = c.protos[f.desc].(*descriptorpb.FieldDescriptorProto)
+				field.Comments = cloneComment(f.Comments)
+				field.Message = &msg
+				c.fields = append(c.fields, &field)
+				msg.Fields[j] = &field
+			}
+		}
+
+		msgs[i] = &msg
+	}
+
+	return msgs
+}
+
+func (c *cloner) cloneServices(orig []*PKServiceDescriptor) []*PKServiceDescriptor {
+	if orig == nil {
+		return nil
+	}
+
+	svcs := make([]*PKServiceDescriptor, len(orig))
+	for i, s := range orig {
+		svc := *s
+		svc.common = c.cloneCommon(s.common)
+		svc.desc = c.protos[s.desc].(*descriptorpb.ServiceDescriptorProto)
+		svc.Comments = cloneComment(s.Comments)
+
+		if s.Methods != nil {
+			svc.Methods = make([]*PKMethodDescriptor, len(s.Methods))
+			for j, m := range s.Methods {
+				method := *m
+				method.common = c.cloneCommon(m.common)
+				method.desc = c.protos[m.desc].(*descriptorpb.MethodDescriptorProto)
+				method.Comments = cloneComment(m.Comments)
+				method.Service = &svc
+				c.methods[m] = &method
+				svc.Methods[j] = &method
+			}
+		}
+
+		svcs[i] = &svc
+	}
+
+	return svcs
+}
+
+// rewire points the type references of the cloned descriptors at the clones of descriptors defined within the file
+func (c *cloner) rewire() {
+	message := func(m *PKDescriptor) *PKDescriptor {
+		if clone, ok := c.messages[m]; ok {
+			return clone
+		}
+		return m
+	}
+
+	methods := func(orig []*PKMethodDescriptor) []*PKMethodDescriptor {
+		if orig == nil {
+			return nil
+		}
+
+		ms := make([]*PKMethodDescriptor, len(orig))
+		for i, m := range orig {
+			ms[i] = m
+			if clone, ok := c.methods[m]; ok {
+				ms[i] = clone
+			}
+		}
+		return ms
+	}
+
+	for _, f := range c.fields {
+		f.MessageType = message(f.MessageType)
+		if clone, ok := c.enums[f.EnumType]; ok {
+			f.EnumType = clone
+		}
+	}
+
+	for _, ext := range c.extensions {
+		ext.ExtendedMessage = message(ext.ExtendedMessage)
+	}
+
+	for _, m := range c.methods {
+		m.InputType = message(m.InputType)
+		m.OutputType = message(m.OutputType)
+	}
+
+	for _, m := range c.messages {
+		m.requestOf = methods(m.requestOf)
+		m.responseOf = methods(m.responseOf)
+	}
+}
@@ -0,0 +1,63 @@
+package protokit
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestClone(t *testing.T) {
+	orig := parseFixtureFile(t, "shop", "shop/shop.proto")
+	clone := orig.Clone()
+
+	item, origItem := clone.GetMessage("Item"), orig.GetMessage("Item")
+	if item == origItem {
+		t.Fatal("Clone() shares the Item message with the original")
+	}
+
+	// references within the file point at the copies
+	detail := item.GetMessage("Detail")
+	if got := detail.GetParent(); got != item {
+		t.Errorf("Item.Detail.GetParent() = %p, want the cloned Item %p", got, item)
+	}
+	if got := item.GetMessageField("detail").GetMessageType(); got != detail {
+		t.Errorf("detail.GetMessageType() = %p, want the cloned Item.Detail %p", got, detail)
+	}
+	if got := item.GetMessageField("detail").GetFile(); got != clone {
+		t.Errorf("detail.GetFile() = %p, want the clone %p", got, clone)
+	}
+	if got := clone.GetService("Shop").GetNamedMethod("GetItem").GetOutputType(); got != item {
+		t.Errorf("GetItem.GetOutputType() = %p, want the cloned Item %p", got, item)
+	}
+
+	// while types defined in other files are shared
+	if got, want := item.GetMessageField("price").GetMessageType(), origItem.GetMessageField("price").GetMessageType(); got != want {
+		t.Errorf("price.GetMessageType() = %p, want the original common.Money %p", got, want)
+	}
+
+	// mutating the clone leaves the original untouched
+	item.ProtoDesc().Name = proto.String("Thing")
+	item.ProtoDesc().Field = item.ProtoDesc().Field[:1]
+	item.Fields = item.Fields[:1]
+	item.GetComments().Leading = "changed"
+	clone.ProtoDesc().GetOptions().GoPackage = proto.String("example.com/other")
+
+	if got := origItem.GetName(); got != "Item" {
+		t.Errorf("original Item.GetName() = %q, want Item", got)
+	}
+	if got := len(origItem.GetMessageFields()); got != 11 {
+		t.Errorf("original Item has %d fields, want 11", got)
+	}
+	if got := len(origItem.ProtoDesc().GetField()); got != 11 {
+		t.Errorf("original Item proto has %d fields, want 11", got)
+	}
+	if got := origItem.GetComments().GetLeading(); got != "An item for sale." {
+		t.Errorf("original Item comments = %q, want %q", got, "An item for sale.")
+	}
+	if got := orig.ProtoDesc().GetOptions().GetGoPackage(); got != "example.com/gen/shop;shop" {
+		t.Errorf("original go_package = %q, want %q", got, "example.com/gen/shop;shop")
+	}
+	if got := orig.GetMessage("Item"); got != origItem {
+		t.Error("original GetMessage(\"Item\") changed")
+	}
+}
@@ -2,9 +2,12 @@ package protokit
 
 import (
 	"bytes"
-	"google.golang.org/protobuf/types/descriptorpb"
+	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // A Comment describes the leading, trailing, and detached comments for a proto object. See `SourceCodeInfo_Location` in
@@ -29,27 +32,72 @@ func (c *Comment) String() string {
 	return strings.TrimSpace(b.String())
 }
 
-func newComment(loc *descriptorpb.SourceCodeInfo_Location) *Comment {
+// CommentTrimPolicy controls how the text of comments is normalized when a file is parsed
+type CommentTrimPolicy int
+
+const (
+	// CommentClean removes the space following the comment markers at the start of each line, as well as the leading and
+	// trailing whitespace of the comment. This is the default.
+	CommentClean CommentTrimPolicy = iota
+	// CommentTrimSpace only removes the leading and trailing whitespace of the comment
+	CommentTrimSpace
+	// CommentVerbatim keeps comments exactly as protoc stored them, including the leading space of each line and the
+	// trailing line break
+	CommentVerbatim
+)
+
+// apply normalizes the comment text according to the policy
+func (p CommentTrimPolicy) apply(str string) string {
+	switch p {
+	case CommentVerbatim:
+		return str
+	case CommentTrimSpace:
+		return strings.TrimSpace(str)
+	default:
+		return scrub(str)
+	}
+}
+
+func newComment(loc *descriptorpb.SourceCodeInfo_Location, policy CommentTrimPolicy) *Comment {
 	detached := make([]string, len(loc.GetLeadingDetachedComments()))
 	for i, c := range loc.GetLeadingDetachedComments() {
-		detached[i] = scrub(c)
+		detached[i] = policy.apply(c)
 	}
 
 	return &Comment{
-		Leading:  scrub(loc.GetLeadingComments()),
-		Trailing: scrub(loc.GetTrailingComments()),
+		Leading:  policy.apply(loc.GetLeadingComments()),
+		Trailing: policy.apply(loc.GetTrailingComments()),
 		Detached: detached,
 	}
 }
 
-// GetLeading returns the leading comments
-func (c *Comment) GetLeading() string { return c.Leading }
+// GetLeading returns the leading comments, i.e. the comment lines directly preceding the declaration
+func (c *Comment) GetLeading() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.Leading
+}
 
-// GetTrailing returns the leading comments
-func (c *Comment) GetTrailing() string { return c.Trailing }
+// GetTrailing returns the trailing comments, i.e. the comment following the declaration on the same line (or directly
+// below it)
+func (c *Comment) GetTrailing() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.Trailing
+}
 
 // GetDetached returns the detached leading comments
-func (c *Comment) GetDetached() []string { return c.Detached }
+func (c *Comment) GetDetached() []string {
+	if c == nil {
+		return nil
+	}
+
+	return c.Detached
+}
 
 // Comments is a map of source location paths to values.
 type Comments map[string]*Comment
@@ -57,27 +105,30 @@ type Comments map[string]*Comment
 // ParseComments parses all comments within a proto file. The locations are encoded into the map by joining the paths
 // with a "." character. E.g. `4.2.3.0`.
 //
-// Leading/trailing spaces are trimmed for each comment type (leading, trailing, detached)
+// Leading/trailing spaces are trimmed for each comment type (leading, trailing, detached), as with `CommentClean`
 func ParseComments(fd *descriptorpb.FileDescriptorProto) Comments {
-	comments := make(Comments)
+	_, comments := parseLocationsAndComments(fd, CommentClean)
+	return comments
+}
 
-	for _, loc := range fd.GetSourceCodeInfo().GetLocation() {
-		if loc.GetLeadingComments() == "" && loc.GetTrailingComments() == "" && len(loc.GetLeadingDetachedComments()) == 0 {
-			continue
-		}
+func hasComments(loc *descriptorpb.SourceCodeInfo_Location) bool {
+	return loc.GetLeadingComments() != "" || loc.GetTrailingComments() != "" || len(loc.GetLeadingDetachedComments()) > 0
+}
 
-		path := loc.GetPath()
-		key := make([]string, len(path))
-		for idx, p := range path {
-			key[idx] = strconv.Itoa(int(p))
+// pathKey joins the elements of a SourceCodeInfo path with a "." character
+func pathKey(path []int32) string {
+	key := make([]byte, 0, len(path)*3)
+	for idx, p := range path {
+		if idx > 0 {
+			key = append(key, '.')
 		}
-
-		comments[strings.Join(key, ".")] = newComment(loc)
+		key = strconv.AppendInt(key, int64(p), 10)
 	}
 
-	return comments
+	return string(key)
 }
 
+// Get returns the comment at the given path, or an empty comment if there's none. Lookups are a single map access.
 func (c Comments) Get(path string) *Comment {
 	if val, ok := c[path]; ok {
 		return val
@@ -90,3 +141,112 @@ func (c Comments) Get(path string) *Comment {
 func scrub(str string) string {
 	return strings.TrimSpace(strings.Replace(str, "\n ", "\n", -1))
 }
+
+// StripComments replaces the comments of the file and every descriptor within it with empty comments. When
+// stripSourceCodeInfo is true, the comments are also removed from the locations of the underlying SourceCodeInfo (the
+// locations themselves are kept). Combine with `Clone` to keep the original file intact.
+func StripComments(file *PKFileDescriptor, stripSourceCodeInfo bool) {
+	file.comments = make(Comments)
+	file.PackageComments = file.comments.Get("")
+	file.SyntaxComments = file.comments.Get("")
+
+	stripMapEntries := func(msg *PKDescriptor) {
+		for _, entry := range msg.GetMapEntries() {
+			entry.Comments = file.comments.Get("")
+			for _, f := range entry.GetMessageFields() {
+				f.Comments = file.comments.Get("")
+			}
+		}
+	}
+
+	Walk(file, func(d Descriptor) bool {
+		empty := file.comments.Get("")
+		switch desc := d.(type) {
+		case *PKDescriptor:
+			desc.Comments = empty
+			stripMapEntries(desc)
+		case *PKFieldDescriptor:
+			desc.Comments = empty
+		case *PKEnumDescriptor:
+			desc.Comments = empty
+		case *PKEnumValueDescriptor:
+			desc.Comments = empty
+		case *PKExtensionDescriptor:
+			desc.Comments = empty
+		case *PKServiceDescriptor:
+			desc.Comments = empty
+		case *PKMethodDescriptor:
+			desc.Comments = empty
+		}
+
+		return true
+	})
+
+	if stripSourceCodeInfo {
+		for _, loc := range file.ProtoDesc().GetSourceCodeInfo().GetLocation() {
+			loc.LeadingComments = nil
+			loc.TrailingComments = nil
+			loc.LeadingDetachedComments = nil
+		}
+	}
+}
+
+// UnmatchedCommentPaths returns the sorted paths of the file's comments that aren't attached to the file (its package or
+// syntax statement) or to any of its descriptors, including map entries. This is a diagnostic aid for the construction
+// of comment paths: besides parser gaps, it reports comments on elements without a descriptor of their own, such as
+// option statements, reserved ranges or oneofs.
+func UnmatchedCommentPaths(file *PKFileDescriptor) []string {
+	matched := map[string]bool{
+		fmt.Sprintf("%d", packageCommentPath): true,
+		fmt.Sprintf("%d", syntaxCommentPath):  true,
+	}
+
+	Walk(file, func(d Descriptor) bool {
+		matched[d.GetPath()] = true
+		if msg, ok := d.(*PKDescriptor); ok {
+			for _, entry := range msg.GetMapEntries() {
+				matched[entry.GetPath()] = true
+				for _, f := range entry.GetMessageFields() {
+					matched[f.GetPath()] = true
+				}
+			}
+		}
+
+		return true
+	})
+
+	paths := make([]string, 0)
+	for path := range file.comments {
+		if !matched[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// parseLocations returns the SourceCodeInfo locations of the file keyed by their path (see `ParseComments`)
+func parseLocations(fd *descriptorpb.FileDescriptorProto) map[string]*descriptorpb.SourceCodeInfo_Location {
+	locations, _ := parseLocationsAndComments(fd, CommentClean)
+	return locations
+}
+
+// parseLocationsAndComments returns both the locations and the comments of the file in a single pass over its
+// SourceCodeInfo, so that each path key is only built once. The comments are normalized according to the policy.
+func parseLocationsAndComments(fd *descriptorpb.FileDescriptorProto,
+	policy CommentTrimPolicy) (map[string]*descriptorpb.SourceCodeInfo_Location, Comments) {
+	locs := fd.GetSourceCodeInfo().GetLocation()
+	locations := make(map[string]*descriptorpb.SourceCodeInfo_Location, len(locs))
+	comments := make(Comments, len(locs))
+
+	for _, loc := range locs {
+		key := pathKey(loc.GetPath())
+		locations[key] = loc
+		if hasComments(loc) {
+			comments[key] = newComment(loc, policy)
+		}
+	}
+
+	return locations, comments
+}
@@ -0,0 +1,290 @@
+package protokit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestGetCommentForPath(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+
+	// the first field (2) of the first message (4) of the file
+	c := f.GetCommentForPath(fmt.Sprintf("%d.%d.%d.%d", 4, 0, 2, 0))
+	if got, want := c.GetLeading(), "The identifier."; got != want {
+		t.Errorf("GetLeading() = %q, want %q", got, want)
+	}
+	if got, want := c.GetTrailing(), "trailing comment"; got != want {
+		t.Errorf("GetTrailing() = %q, want %q", got, want)
+	}
+
+	if c := f.GetCommentForPath("4.0.2.1"); c == nil || c.String() != "" {
+		t.Errorf(`GetCommentForPath("4.0.2.1") = %v, want an empty comment`, c)
+	}
+	if c := f.GetCommentForPath("4.99"); c == nil || c.String() != "" {
+		t.Errorf(`GetCommentForPath("4.99") = %v, want an empty comment`, c)
+	}
+}
+
+func TestMapFieldComments(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	const want = "Counts by warehouse."
+	if got := item.GetMessageField("counts").GetComments().GetLeading(); got != want {
+		t.Errorf("counts.GetComments().GetLeading() = %q, want %q", got, want)
+	}
+
+	entry := item.GetMessageField("counts").GetMessageType()
+	if !entry.IsMapEntry() {
+		t.Fatalf("counts.GetMessageType() = %v, want the map entry", entry.GetFullName())
+	}
+	if got := entry.GetComments().GetLeading(); got != want {
+		t.Errorf("CountsEntry.GetComments().GetLeading() = %q, want %q", got, want)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	orig := parseFixtureFile(t, "shop", "shop/shop.proto")
+	f := orig.Clone()
+	StripComments(f, true)
+
+	isEmpty := func(c *Comment) bool {
+		return c != nil && c.GetLeading() == "" && c.GetTrailing() == "" && len(c.GetDetached()) == 0
+	}
+
+	if !isEmpty(f.GetPackageComments()) || !isEmpty(f.GetSyntaxComments()) {
+		t.Errorf("package comments = %v, syntax comments = %v, want empty comments", f.GetPackageComments(),
+			f.GetSyntaxComments())
+	}
+	Walk(f, func(d Descriptor) bool {
+		if !isEmpty(d.GetComments()) {
+			t.Errorf("%s.GetComments() = %v, want an empty comment", d.GetFullName(), d.GetComments())
+		}
+		if m, ok := d.(*PKDescriptor); ok {
+			for _, entry := range m.GetMapEntries() {
+				if !isEmpty(entry.GetComments()) {
+					t.Errorf("%s.GetComments() = %v, want an empty comment", entry.GetFullName(), entry.GetComments())
+				}
+			}
+		}
+		return true
+	})
+	if c := f.GetCommentForPath("4.0.2.0"); !isEmpty(c) {
+		t.Errorf(`GetCommentForPath("4.0.2.0") = %v, want an empty comment`, c)
+	}
+
+	locations := f.ProtoDesc().GetSourceCodeInfo().GetLocation()
+	if len(locations) == 0 {
+		t.Fatal("the source code info locations were removed")
+	}
+	for _, loc := range locations {
+		if loc.LeadingComments != nil || loc.TrailingComments != nil || len(loc.GetLeadingDetachedComments()) > 0 {
+			t.Errorf("location %v still has comments", loc.GetPath())
+		}
+	}
+
+	// the original is left intact
+	if got := orig.GetMessage("Item").GetComments().GetLeading(); got != "An item for sale." {
+		t.Errorf("original Item comments = %q, want %q", got, "An item for sale.")
+	}
+	if got := orig.ProtoDesc().GetSourceCodeInfo().GetLocation()[0].GetLeadingComments(); got == "" {
+		t.Error("the comments of the original source code info were removed")
+	}
+}
+
+func TestLeadingAndTrailingComments(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+	item := f.GetMessage("Item")
+	level := item.GetMessage("Detail").GetEnum("Level")
+
+	tests := []struct {
+		desc              Descriptor
+		leading, trailing string
+	}{
+		{desc: item.GetMessageField("item_id"), leading: "The identifier.", trailing: "trailing comment"},
+		{desc: level.GetNamedValue("LEVEL_UNSPECIFIED"), leading: "Unknown level."},
+		{desc: level.GetNamedValue("LEVEL_HIGH"), trailing: "The highest level."},
+		{desc: item.GetMessageField("price")},
+	}
+
+	for _, tt := range tests {
+		c := tt.desc.GetComments()
+		if got := c.GetLeading(); got != tt.leading {
+			t.Errorf("%s: GetLeading() = %q, want %q", tt.desc.GetLongName(), got, tt.leading)
+		}
+		if got := c.GetTrailing(); got != tt.trailing {
+			t.Errorf("%s: GetTrailing() = %q, want %q", tt.desc.GetLongName(), got, tt.trailing)
+		}
+	}
+
+	// straight from the source code info
+	c := ParseComments(f.ProtoDesc()).Get("4.0.2.0")
+	if c.GetLeading() != "The identifier." || c.GetTrailing() != "trailing comment" {
+		t.Errorf("ParseComments()[4.0.2.0] = %q / %q, want %q / %q", c.GetLeading(), c.GetTrailing(),
+			"The identifier.", "trailing comment")
+	}
+
+	var nilComment *Comment
+	if nilComment.GetLeading() != "" || nilComment.GetTrailing() != "" {
+		t.Error("a nil comment has non-empty leading or trailing comments")
+	}
+}
+
+func TestNestedEnumValueComments(t *testing.T) {
+	// Item.Detail.Level is nested two levels deep, so its values' paths go through both messages
+	level := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item").GetMessage("Detail").GetEnum("Level")
+
+	if got, want := level.GetComments().GetLeading(), "Levels of detail."; got != want {
+		t.Errorf("Level.GetComments().GetLeading() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name, path        string
+		leading, trailing string
+	}{
+		{name: "LEVEL_UNSPECIFIED", path: "4.0.3.1.4.0.2.0", leading: "Unknown level."},
+		{name: "LEVEL_HIGH", path: "4.0.3.1.4.0.2.1", trailing: "The highest level."},
+	}
+
+	for _, tt := range tests {
+		v := level.GetNamedValue(tt.name)
+		if v == nil {
+			t.Fatalf("GetNamedValue(%q) = nil", tt.name)
+		}
+		if got := v.GetPath(); got != tt.path {
+			t.Errorf("%s: GetPath() = %q, want %q", tt.name, got, tt.path)
+		}
+		if got := v.GetComments().GetLeading(); got != tt.leading {
+			t.Errorf("%s: GetLeading() = %q, want %q", tt.name, got, tt.leading)
+		}
+		if got := v.GetComments().GetTrailing(); got != tt.trailing {
+			t.Errorf("%s: GetTrailing() = %q, want %q", tt.name, got, tt.trailing)
+		}
+	}
+}
+
+// newCommentedFile returns a file with n messages of 10 fields each, every one of them carrying a leading comment
+func newCommentedFile(n int) *descriptorpb.FileDescriptorProto {
+	info := new(descriptorpb.SourceCodeInfo)
+	for i := 0; i < n; i++ {
+		info.Location = append(info.Location, &descriptorpb.SourceCodeInfo_Location{
+			Path:            []int32{messageCommentPath, int32(i)},
+			LeadingComments: proto.String(fmt.Sprintf(" Message %d.\n", i)),
+		})
+		for j := 0; j < 10; j++ {
+			info.Location = append(info.Location, &descriptorpb.SourceCodeInfo_Location{
+				Path:             []int32{messageCommentPath, int32(i), messageFieldCommentPath, int32(j)},
+				LeadingComments:  proto.String(fmt.Sprintf(" Field %d.\n", j)),
+				TrailingComments: proto.String(" trailing\n"),
+			})
+		}
+	}
+
+	return &descriptorpb.FileDescriptorProto{Name: proto.String("bench.proto"), SourceCodeInfo: info}
+}
+
+func BenchmarkParseComments(b *testing.B) {
+	// 5500 locations
+	fd := newCommentedFile(500)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if comments := ParseComments(fd); len(comments) != 5500 {
+			b.Fatalf("ParseComments() = %d comments, want 5500", len(comments))
+		}
+	}
+}
+
+func TestUnmatchedCommentPaths(t *testing.T) {
+	req := loadFixture(t, "shop", "shop/shop.proto")
+	for _, fd := range req.GetProtoFile() {
+		if fd.GetName() != "shop/shop.proto" {
+			continue
+		}
+
+		info := fd.GetSourceCodeInfo()
+		info.Location = append(info.Location,
+			// the key of the Item.counts map entry
+			&descriptorpb.SourceCodeInfo_Location{Path: []int32{4, 0, 3, 0, 2, 0}, Span: []int32{21, 2, 32}, LeadingComments: proto.String(" Key.\n")},
+			// the go_package option statement
+			&descriptorpb.SourceCodeInfo_Location{Path: []int32{8}, Span: []int32{11, 0, 42}, LeadingComments: proto.String(" Options.\n")},
+		)
+	}
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if f.GetName() != "shop/shop.proto" {
+			continue
+		}
+
+		// the comments of the nested enum values and map entries are matched, not those of the options or the oneof
+		want := []string{"4.0.8.0", "8"}
+		if got := UnmatchedCommentPaths(f); !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmatchedCommentPaths() = %q, want %q", got, want)
+		}
+	}
+
+	if got := UnmatchedCommentPaths(parseFixtureFile(t, "legacy", "legacy/legacy.proto")); got == nil || len(got) != 0 {
+		t.Errorf("UnmatchedCommentPaths() = %q, want an empty slice", got)
+	}
+}
+
+func TestWithCommentTrimPolicy(t *testing.T) {
+	req := loadFixture(t, "shop", "shop/shop.proto")
+	for _, fd := range req.GetProtoFile() {
+		if fd.GetName() != "shop/shop.proto" {
+			continue
+		}
+
+		// a comment on two lines for GetItemRequest, detached from another one
+		fd.SourceCodeInfo.Location = append(fd.SourceCodeInfo.Location, &descriptorpb.SourceCodeInfo_Location{
+			Path:                    []int32{4, 1},
+			Span:                    []int32{54, 0, 63, 1},
+			LeadingComments:         proto.String(" Requests an item.\n Or several.\n"),
+			LeadingDetachedComments: []string{" Detached.\n"},
+		})
+	}
+
+	tests := []struct {
+		policy   CommentTrimPolicy
+		leading  string
+		trailing string
+		detached string
+	}{
+		{policy: CommentClean, leading: "Requests an item.\nOr several.", trailing: "trailing comment", detached: "Detached."},
+		{policy: CommentTrimSpace, leading: "Requests an item.\n Or several.", trailing: "trailing comment", detached: "Detached."},
+		{policy: CommentVerbatim, leading: " Requests an item.\n Or several.\n", trailing: " trailing comment\n", detached: " Detached.\n"},
+	}
+
+	for _, tt := range tests {
+		files, err := ParseCodeGenRequestAllFiles(proto.Clone(req).(*pluginpb.CodeGeneratorRequest), WithCommentTrimPolicy(tt.policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, f := range files {
+			if f.GetName() != "shop/shop.proto" {
+				continue
+			}
+
+			c := f.GetMessage("GetItemRequest").GetComments()
+			if c.GetLeading() != tt.leading {
+				t.Errorf("policy %d: leading comment = %q, want %q", tt.policy, c.GetLeading(), tt.leading)
+			}
+			if got := c.GetDetached(); len(got) != 1 || got[0] != tt.detached {
+				t.Errorf("policy %d: detached comments = %q, want [%q]", tt.policy, got, tt.detached)
+			}
+			if got := f.GetMessage("Item").GetMessageField("item_id").GetComments().GetTrailing(); got != tt.trailing {
+				t.Errorf("policy %d: trailing comment = %q, want %q", tt.policy, got, tt.trailing)
+			}
+		}
+	}
+}
@@ -12,6 +12,11 @@ const (
 	descriptorContextKey = contextKey("descriptor")
 	enumContextKey       = contextKey("enum")
 	serviceContextKey    = contextKey("service")
+	fieldContextKey      = contextKey("field")
+	methodContextKey     = contextKey("method")
+	extensionContextKey  = contextKey("extension")
+	enumValueContextKey  = contextKey("enum_value")
+	optionsContextKey    = contextKey("options")
 )
 
 // ContextWithAllFiles returns a new context with the attached `AllFiles`
@@ -68,3 +73,63 @@ func ServiceDescriptorFromContext(ctx context.Context) (*PKServiceDescriptor, bo
 	val, ok := ctx.Value(serviceContextKey).(*PKServiceDescriptor)
 	return val, ok
 }
+
+// ContextWithFieldDescriptor returns a new context with the specified `PKFieldDescriptor`
+func ContextWithFieldDescriptor(ctx context.Context, field *PKFieldDescriptor) context.Context {
+	return context.WithValue(ctx, fieldContextKey, field)
+}
+
+// FieldDescriptorFromContext returns the associated `PKFieldDescriptor` for the context and whether or not it was found
+func FieldDescriptorFromContext(ctx context.Context) (*PKFieldDescriptor, bool) {
+	val, ok := ctx.Value(fieldContextKey).(*PKFieldDescriptor)
+	return val, ok
+}
+
+// ContextWithMethodDescriptor returns a new context with the specified `PKMethodDescriptor`
+func ContextWithMethodDescriptor(ctx context.Context, method *PKMethodDescriptor) context.Context {
+	return context.WithValue(ctx, methodContextKey, method)
+}
+
+// MethodDescriptorFromContext returns the associated `PKMethodDescriptor` for the context and whether or not it was found
+func MethodDescriptorFromContext(ctx context.Context) (*PKMethodDescriptor, bool) {
+	val, ok := ctx.Value(methodContextKey).(*PKMethodDescriptor)
+	return val, ok
+}
+
+// ContextWithExtensionDescriptor returns a new context with the specified `PKExtensionDescriptor`
+func ContextWithExtensionDescriptor(ctx context.Context, ext *PKExtensionDescriptor) context.Context {
+	return context.WithValue(ctx, extensionContextKey, ext)
+}
+
+// ExtensionDescriptorFromContext returns the associated `PKExtensionDescriptor` for the context and whether or not it was
+// found
+func ExtensionDescriptorFromContext(ctx context.Context) (*PKExtensionDescriptor, bool) {
+	val, ok := ctx.Value(extensionContextKey).(*PKExtensionDescriptor)
+	return val, ok
+}
+
+// ContextWithEnumValueDescriptor returns a new context with the specified `PKEnumValueDescriptor`
+func ContextWithEnumValueDescriptor(ctx context.Context, value *PKEnumValueDescriptor) context.Context {
+	return context.WithValue(ctx, enumValueContextKey, value)
+}
+
+// EnumValueDescriptorFromContext returns the associated `PKEnumValueDescriptor` for the context and whether or not it was
+// found
+func EnumValueDescriptorFromContext(ctx context.Context) (*PKEnumValueDescriptor, bool) {
+	val, ok := ctx.Value(enumValueContextKey).(*PKEnumValueDescriptor)
+	return val, ok
+}
+
+// contextWithParseOptions returns a new context with the attached parse options
+func contextWithParseOptions(ctx context.Context, opts *parseOptions) context.Context {
+	return context.WithValue(ctx, optionsContextKey, opts)
+}
+
+// parseOptionsFromContext returns the parse options from the context, or the default options if none were attached
+func parseOptionsFromContext(ctx context.Context) *parseOptions {
+	if val, ok := ctx.Value(optionsContextKey).(*parseOptions); ok {
+		return val
+	}
+
+	return newParseOptions(nil)
+}
@@ -0,0 +1,55 @@
+package protokit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFieldAndMethodContext(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+	field := f.GetMessage("Item").GetMessageField("item_id")
+	method := f.GetService("Shop").GetNamedMethod("GetItem")
+
+	ctx := ContextWithMethodDescriptor(ContextWithFieldDescriptor(context.Background(), field), method)
+	if got, ok := FieldDescriptorFromContext(ctx); !ok || got != field {
+		t.Errorf("FieldDescriptorFromContext() = %v, %t, want item_id", got, ok)
+	}
+	if got, ok := MethodDescriptorFromContext(ctx); !ok || got != method {
+		t.Errorf("MethodDescriptorFromContext() = %v, %t, want GetItem", got, ok)
+	}
+
+	// the keys don't collide with the other descriptors
+	if got, ok := DescriptorFromContext(ctx); ok {
+		t.Errorf("DescriptorFromContext() = %v, want not found", got)
+	}
+	if got, ok := FieldDescriptorFromContext(context.Background()); ok || got != nil {
+		t.Errorf("FieldDescriptorFromContext(Background) = %v, %t, want nil, false", got, ok)
+	}
+	if got, ok := MethodDescriptorFromContext(context.Background()); ok || got != nil {
+		t.Errorf("MethodDescriptorFromContext(Background) = %v, %t, want nil, false", got, ok)
+	}
+}
+
+func TestExtensionAndEnumValueContext(t *testing.T) {
+	f := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+	ext := f.GetExtensions()[0]
+	value := f.GetEnum("Status").GetNamedValue("ACTIVE")
+
+	ctx := ContextWithEnumValueDescriptor(ContextWithExtensionDescriptor(context.Background(), ext), value)
+	if got, ok := ExtensionDescriptorFromContext(ctx); !ok || got != ext {
+		t.Errorf("ExtensionDescriptorFromContext() = %v, %t, want %s", got, ok, ext.GetName())
+	}
+	if got, ok := EnumValueDescriptorFromContext(ctx); !ok || got != value {
+		t.Errorf("EnumValueDescriptorFromContext() = %v, %t, want ACTIVE", got, ok)
+	}
+
+	if got, ok := EnumDescriptorFromContext(ctx); ok {
+		t.Errorf("EnumDescriptorFromContext() = %v, want not found", got)
+	}
+	if got, ok := ExtensionDescriptorFromContext(context.Background()); ok || got != nil {
+		t.Errorf("ExtensionDescriptorFromContext(Background) = %v, %t, want nil, false", got, ok)
+	}
+	if got, ok := EnumValueDescriptorFromContext(context.Background()); ok || got != nil {
+		t.Errorf("EnumValueDescriptorFromContext(Background) = %v, %t, want nil, false", got, ok)
+	}
+}
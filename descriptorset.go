@@ -0,0 +1,105 @@
+package protokit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ToFileDescriptorSet returns a FileDescriptorSet made of the descriptor protos of the files. Files are ordered so that
+// dependencies come before the files importing them, and dependencies missing from files are added, so the result can
+// be passed to `protodesc.NewFiles`.
+func ToFileDescriptorSet(files []*PKFileDescriptor) *descriptorpb.FileDescriptorSet {
+	set := new(descriptorpb.FileDescriptorSet)
+	for _, f := range dependencyOrder(files) {
+		set.File = append(set.File, f.ProtoDesc())
+	}
+
+	return set
+}
+
+// dependencyOrder returns the files and their (transitive) dependencies with every file placed after its dependencies.
+// Files are otherwise kept in the supplied order.
+func dependencyOrder(files []*PKFileDescriptor) []*PKFileDescriptor {
+	ordered := make([]*PKFileDescriptor, 0, len(files))
+	visited := make(map[*PKFileDescriptor]bool)
+
+	var visit func(f *PKFileDescriptor)
+	visit = func(f *PKFileDescriptor) {
+		if f == nil || visited[f] {
+			return
+		}
+
+		visited[f] = true
+		for _, dep := range f.GetDependencies() {
+			visit(dep)
+		}
+		ordered = append(ordered, f)
+	}
+
+	for _, f := range files {
+		visit(f)
+	}
+
+	return ordered
+}
+
+// SortFilesByDependency returns the files sorted so that every file comes after the files it imports. Files that don't
+// depend on each other are sorted by name, making the result deterministic. Only dependencies within files are taken
+// into account. An error is returned if the files contain an import cycle.
+func SortFilesByDependency(files []*PKFileDescriptor) ([]*PKFileDescriptor, error) {
+	inSet := make(map[*PKFileDescriptor]bool, len(files))
+	for _, f := range files {
+		inSet[f] = true
+	}
+
+	pending := make(map[*PKFileDescriptor]int, len(files))
+	dependents := make(map[*PKFileDescriptor][]*PKFileDescriptor)
+	for f := range inSet {
+		for _, dep := range f.GetDependencies() {
+			if inSet[dep] {
+				pending[f]++
+				dependents[dep] = append(dependents[dep], f)
+			}
+		}
+	}
+
+	ready := make([]*PKFileDescriptor, 0)
+	for f := range inSet {
+		if pending[f] == 0 {
+			ready = append(ready, f)
+		}
+	}
+
+	sorted := make([]*PKFileDescriptor, 0, len(inSet))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return ready[i].GetName() < ready[j].GetName() })
+
+		f := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, f)
+
+		for _, dependent := range dependents[f] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(sorted) != len(inSet) {
+		cyclic := make([]string, 0)
+		for f, n := range pending {
+			if n > 0 {
+				cyclic = append(cyclic, f.GetName())
+			}
+		}
+
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("import cycle between files: %s", strings.Join(cyclic, ", "))
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,220 @@
+package protokit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestToFileDescriptorSet(t *testing.T) {
+	files := parseFixture(t, "shop")
+
+	// only the file to generate is passed, its dependencies are added
+	set := ToFileDescriptorSet([]*PKFileDescriptor{files["shop/shop.proto"]})
+
+	names := make([]string, 0, len(set.GetFile()))
+	for _, f := range set.GetFile() {
+		names = append(names, f.GetName())
+	}
+	want := []string{
+		"common/money.proto", "common/reexport.proto", "google/protobuf/timestamp.proto", "extra/weak.proto",
+		"shop/shop.proto",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("ToFileDescriptorSet() files = %q, want %q", names, want)
+	}
+
+	if _, err := protodesc.NewFiles(set); err != nil {
+		t.Fatalf("protodesc.NewFiles() error = %v", err)
+	}
+
+	// the set parses back to the same descriptors
+	reparsed, err := ParseFileDescriptorSet(set, []string{"shop/shop.proto"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range reparsed {
+		orig, ok := files[f.GetName()]
+		if !ok {
+			t.Errorf("unexpected file %s", f.GetName())
+			continue
+		}
+		if !proto.Equal(f.ProtoDesc(), orig.ProtoDesc()) {
+			t.Errorf("%s differs once round-tripped", f.GetName())
+		}
+	}
+	if len(reparsed) != len(want) {
+		t.Errorf("ParseFileDescriptorSet() = %d files, want %d", len(reparsed), len(want))
+	}
+}
+
+// newDependentFiles returns files named after the keys of deps, each importing the files listed for it
+func newDependentFiles(deps map[string][]string) []*PKFileDescriptor {
+	byName := make(map[string]*PKFileDescriptor, len(deps))
+	for name := range deps {
+		byName[name] = &PKFileDescriptor{desc: &descriptorpb.FileDescriptorProto{Name: proto.String(name)}}
+	}
+
+	files := make([]*PKFileDescriptor, 0, len(deps))
+	for name, imports := range deps {
+		for _, imp := range imports {
+			byName[name].Dependencies = append(byName[name].Dependencies, byName[imp])
+		}
+		files = append(files, byName[name])
+	}
+
+	return files
+}
+
+func TestSortFilesByDependency(t *testing.T) {
+	tests := []struct {
+		name string
+		deps map[string][]string
+		want []string
+	}{
+		{
+			name: "chain",
+			deps: map[string][]string{"a.proto": {"b.proto"}, "b.proto": {"c.proto"}, "c.proto": nil},
+			want: []string{"c.proto", "b.proto", "a.proto"},
+		},
+		{
+			name: "independent files by name",
+			deps: map[string][]string{"z.proto": {"y.proto"}, "y.proto": nil, "b.proto": nil, "a.proto": {"y.proto"}},
+			want: []string{"b.proto", "y.proto", "a.proto", "z.proto"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := SortFilesByDependency(newDependentFiles(tt.deps))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(sorted))
+			for _, f := range sorted {
+				got = append(got, f.GetName())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortFilesByDependency() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortFilesByDependencyCycle(t *testing.T) {
+	files := newDependentFiles(map[string][]string{
+		"a.proto": {"b.proto"},
+		"b.proto": {"c.proto"},
+		"c.proto": {"a.proto"},
+		"d.proto": nil,
+	})
+
+	_, err := SortFilesByDependency(files)
+	if err == nil || err.Error() != "import cycle between files: a.proto, b.proto, c.proto" {
+		t.Errorf("SortFilesByDependency() error = %v, want an import cycle between a.proto, b.proto and c.proto", err)
+	}
+}
+
+func TestParseFileDescriptorSet(t *testing.T) {
+	// as written by `protoc --descriptor_set_out`, with a Windows path
+	data, err := proto.Marshal(loadFixtureSet(t, "options"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := new(descriptorpb.FileDescriptorSet)
+	if err := proto.Unmarshal(data, set); err != nil {
+		t.Fatal(err)
+	}
+	set.GetFile()[len(set.GetFile())-1].Name = proto.String(`user\user.proto`)
+	orig := proto.Clone(set)
+
+	files, err := ParseFileDescriptorSet(set, []string{`user\user.proto`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(set.GetFile()) {
+		t.Errorf("ParseFileDescriptorSet() = %d files, want %d", len(files), len(set.GetFile()))
+	}
+
+	var user *PKFileDescriptor
+	for _, f := range files {
+		if f.IsFileToGenerate {
+			user = f
+		}
+	}
+	if user == nil || user.GetName() != "user/user.proto" {
+		t.Fatalf("file to generate = %v, want user/user.proto", user)
+	}
+	if got, ok := user.GetFileOptionString("opts.policy"); !ok || got != "strict" {
+		t.Errorf(`GetFileOptionString("opts.policy") = %q, %t, want "strict", true`, got, ok)
+	}
+
+	// neither the names nor the options of the caller's set were rewritten
+	if !proto.Equal(set, orig) {
+		t.Error("ParseFileDescriptorSet() modified the set")
+	}
+	if user.ProtoDesc() == set.GetFile()[len(set.GetFile())-1] {
+		t.Error("the parsed file shares its descriptor proto with the set")
+	}
+
+	// an invalid set is reported rather than aborting
+	var missing descriptorpb.FileDescriptorSet
+	for _, f := range set.GetFile() {
+		if f.GetName() != "opts/options.proto" {
+			missing.File = append(missing.File, f)
+		}
+	}
+	if _, err := ParseFileDescriptorSet(&missing, nil); err == nil {
+		t.Error("ParseFileDescriptorSet() succeeded without the imported opts/options.proto, want an error")
+	}
+}
+
+func TestParseFromFiles(t *testing.T) {
+	set := loadFixtureSet(t, "shop")
+
+	// extra/absent.proto, a weak import, is missing from the registry
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseFromFiles(files, []string{"shop/shop.proto"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]*PKFileDescriptor, len(parsed))
+	for _, f := range parsed {
+		byName[f.GetName()] = f
+	}
+	if len(byName) != len(set.GetFile()) {
+		t.Errorf("ParseFromFiles() = %d files, want %d", len(byName), len(set.GetFile()))
+	}
+	shop := byName["shop/shop.proto"]
+	if !shop.IsFileToGenerate || byName["common/money.proto"].IsFileToGenerate {
+		t.Error("only shop/shop.proto is a file to generate")
+	}
+	if got := shop.GetMessage("Item").GetMessageField("price").GetMessageType(); got != byName["common/money.proto"].GetMessage("Money") {
+		t.Errorf("price.GetMessageType() = %v, want the parsed common.Money", got.GetFullName())
+	}
+
+	// a registry missing a regular import holds a placeholder in its place
+	partial := new(descriptorpb.FileDescriptorSet)
+	for _, f := range set.GetFile() {
+		if f.GetName() != "common/money.proto" {
+			partial.File = append(partial.File, f)
+		}
+	}
+	files, err = protodesc.FileOptions{AllowUnresolvable: true}.NewFiles(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseFromFiles(files, []string{"shop/shop.proto"})
+	if err == nil || !strings.Contains(err.Error(), `imports "common/money.proto"`) {
+		t.Errorf("ParseFromFiles() error = %v, want the missing common/money.proto", err)
+	}
+}
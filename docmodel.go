@@ -0,0 +1,29 @@
+package protokit
+
+// A DocModel bundles the comments of a message and its fields, for rendering documentation from templates
+type DocModel struct {
+	Name    string
+	Comment *Comment
+	Fields  []FieldDoc
+}
+
+// A FieldDoc describes the comment of a field within a `DocModel`
+type FieldDoc struct {
+	FieldName string
+	Comment   *Comment
+}
+
+// GetDocModel returns the comment of the message along with the comments of its fields, in declaration order. Fields
+// without comments are included with an empty comment.
+func (m *PKDescriptor) GetDocModel() *DocModel {
+	model := &DocModel{
+		Name:    m.GetName(),
+		Comment: m.GetComments(),
+		Fields:  make([]FieldDoc, len(m.GetMessageFields())),
+	}
+	for i, f := range m.GetMessageFields() {
+		model.Fields[i] = FieldDoc{FieldName: f.GetName(), Comment: f.GetComments()}
+	}
+
+	return model
+}
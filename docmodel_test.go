@@ -0,0 +1,43 @@
+package protokit
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestGetDocModel(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	model := item.GetDocModel()
+	if model.Name != "Item" || model.Comment.GetLeading() != "An item for sale." {
+		t.Errorf("GetDocModel() = %q / %q, want Item / %q", model.Name, model.Comment.GetLeading(), "An item for sale.")
+	}
+	if len(model.Fields) != item.NumFields() {
+		t.Fatalf("GetDocModel() has %d fields, want %d", len(model.Fields), item.NumFields())
+	}
+
+	tmpl := template.Must(template.New("doc").Parse(
+		`{{.Name}}: {{.Comment.GetLeading}}{{range .Fields}}{{with .Comment.GetLeading}}
+- {{$.Name}}.{{.}}{{end}}{{end}}`))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, model); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Item: An item for sale.
+- Item.The identifier.
+- Item.TODO: rename
+- Item.Counts by warehouse.`
+	if got := b.String(); got != want {
+		t.Errorf("rendered doc model:\n%s\nwant:\n%s", got, want)
+	}
+
+	// fields without comments are kept, in declaration order
+	if f := model.Fields[1]; f.FieldName != "price" || f.Comment.String() != "" {
+		t.Errorf("Fields[1] = %q / %q, want price without comment", f.FieldName, f.Comment.String())
+	}
+	if f := model.Fields[0]; f.FieldName != "item_id" || f.Comment.GetTrailing() != "trailing comment" {
+		t.Errorf("Fields[0] = %q / %q, want item_id with its trailing comment", f.FieldName, f.Comment.GetTrailing())
+	}
+}
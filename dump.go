@@ -0,0 +1,122 @@
+package protokit
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// DumpText renders the descriptor tree of the file in a text format resembling the protobuf text format, for debugging
+// and golden tests. It shows the messages with their fields (label, type and number), the enums with their values, the
+// extensions and the services with their method signatures. Descriptors are rendered in declaration order, so the output
+// is stable for a given file.
+func DumpText(file *PKFileDescriptor) string {
+	d := &textDumper{b: new(strings.Builder)}
+	d.line("file %q {", file.GetName())
+	d.indent++
+	if file.GetPackage() != "" {
+		d.line("package: %q", file.GetPackage())
+	}
+	d.line("syntax: %q", file.GetSyntaxEnum())
+	if file.IsEditions() {
+		d.line("edition: %s", file.GetEdition())
+	}
+	for _, dep := range file.ProtoDesc().GetDependency() {
+		d.line("import: %q", dep)
+	}
+	d.enums(file.GetEnums())
+	d.extensions(file.GetExtensions())
+	for _, msg := range file.GetMessages() {
+		d.message(msg)
+	}
+	for _, svc := range file.GetServices() {
+		d.line("service %s {", svc.GetName())
+		d.indent++
+		for _, m := range svc.GetMethods() {
+			d.line("rpc %s(%s) returns (%s)", m.GetName(),
+				streamType(m.IsClientStreaming(), m.GetInputTypeName()),
+				streamType(m.IsServerStreaming(), m.GetOutputTypeName()))
+		}
+		d.indent--
+		d.line("}")
+	}
+	d.indent--
+	d.line("}")
+
+	return d.b.String()
+}
+
+type textDumper struct {
+	b      *strings.Builder
+	indent int
+}
+
+func (d *textDumper) line(format string, args ...interface{}) {
+	d.b.WriteString(strings.Repeat("  ", d.indent))
+	fmt.Fprintf(d.b, format, args...)
+	d.b.WriteString("\n")
+}
+
+func (d *textDumper) message(msg *PKDescriptor) {
+	d.line("message %s {", msg.GetName())
+	d.indent++
+	d.enums(msg.GetEnums())
+	d.extensions(msg.GetExtensions())
+	for _, f := range msg.GetMessageFields() {
+		if f.IsMap() {
+			d.line("field map<%s, %s> %s = %d", fieldTypeName(f.GetMapKey().ProtoDesc()),
+				fieldTypeName(f.GetMapValue().ProtoDesc()), f.GetName(), f.ProtoDesc().GetNumber())
+			continue
+		}
+		d.line("field %s %s %s = %d", labelName(f.ProtoDesc()), fieldTypeName(f.ProtoDesc()), f.GetName(),
+			f.ProtoDesc().GetNumber())
+	}
+	for _, nested := range msg.GetMessages() {
+		d.message(nested)
+	}
+	d.indent--
+	d.line("}")
+}
+
+func (d *textDumper) enums(enums []*PKEnumDescriptor) {
+	for _, e := range enums {
+		d.line("enum %s {", e.GetName())
+		d.indent++
+		for _, v := range e.GetValues() {
+			d.line("value %s = %d", v.GetName(), v.GetNumber())
+		}
+		d.indent--
+		d.line("}")
+	}
+}
+
+func (d *textDumper) extensions(exts []*PKExtensionDescriptor) {
+	for _, ext := range exts {
+		d.line("extend %s %s %s %s = %d", ext.GetExtendee(), labelName(ext.ProtoDesc()), fieldTypeName(ext.ProtoDesc()),
+			ext.GetName(), ext.GetNumber())
+	}
+}
+
+// labelName returns the lower-cased label of the field (e.g. `optional`)
+func labelName(fd *descriptorpb.FieldDescriptorProto) string {
+	return strings.ToLower(strings.TrimPrefix(fd.GetLabel().String(), "LABEL_"))
+}
+
+// fieldTypeName returns the fully-qualified type name of message and enum fields, or else the lower-cased scalar type
+// (e.g. `int32`)
+func fieldTypeName(fd *descriptorpb.FieldDescriptorProto) string {
+	if fd.GetTypeName() != "" {
+		return fd.GetTypeName()
+	}
+
+	return strings.ToLower(strings.TrimPrefix(fd.GetType().String(), "TYPE_"))
+}
+
+func streamType(stream bool, typeName string) string {
+	if stream {
+		return "stream " + typeName
+	}
+
+	return typeName
+}
@@ -0,0 +1,47 @@
+package protokit
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files under testdata")
+
+func TestDumpText(t *testing.T) {
+	tests := []struct {
+		fixture, file, golden string
+	}{
+		{fixture: "shop", file: "shop/shop.proto", golden: "shop.txt"},
+		{fixture: "legacy", file: "legacy/legacy.proto", golden: "legacy.txt"},
+		{fixture: "editions", file: "ed/ed.proto", golden: "editions.txt"},
+		{fixture: "options", file: "user/user.proto", golden: "user.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			got := DumpText(parseFixtureFile(t, tt.fixture, tt.file))
+
+			path := filepath.Join("testdata", "dump", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("DumpText() differs from %s (run with -update to regenerate it):\n%s", path, got)
+			}
+
+			// the output is stable
+			if again := DumpText(parseFixtureFile(t, tt.fixture, tt.file)); again != got {
+				t.Error("DumpText() differs between two parses of the same file")
+			}
+		})
+	}
+}
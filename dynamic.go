@@ -0,0 +1,103 @@
+package protokit
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newLocalTypes returns a registry containing a dynamic extension type for every extension (top-level or nested)
+// defined by the files. Unlike `protoregistry.GlobalTypes`, it only depends on the descriptors of the request.
+func newLocalTypes(allFileDesc map[string]protoreflect.FileDescriptor) (*protoregistry.Types, error) {
+	types := new(protoregistry.Types)
+
+	var register func(exts protoreflect.ExtensionDescriptors, msgs protoreflect.MessageDescriptors) error
+	register = func(exts protoreflect.ExtensionDescriptors, msgs protoreflect.MessageDescriptors) error {
+		for i := 0; i < exts.Len(); i++ {
+			if err := types.RegisterExtension(dynamicpb.NewExtensionType(exts.Get(i))); err != nil {
+				return err
+			}
+		}
+
+		for i := 0; i < msgs.Len(); i++ {
+			if err := register(msgs.Get(i).Extensions(), msgs.Get(i).Messages()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, fd := range allFileDesc {
+		if err := register(fd.Extensions(), fd.Messages()); err != nil {
+			return nil, err
+		}
+	}
+
+	return types, nil
+}
+
+// dynamicOptions re-interprets the options using the extensions defined in the request, so custom options can be read
+// even when their Go types aren't linked into the plugin. Returns `nil` when there are no options
+func (f *PKFileDescriptor) dynamicOptions(options proto.Message) *dynamicpb.Message {
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return nil
+	}
+
+	data, err := proto.Marshal(options)
+	if err != nil {
+		return nil
+	}
+
+	msg := dynamicpb.NewMessage(options.ProtoReflect().Descriptor())
+	if err := (proto.UnmarshalOptions{Resolver: f.types}).Unmarshal(data, msg); err != nil {
+		return nil
+	}
+
+	return msg
+}
+
+// The GetDynamicOptions accessors return the options of a descriptor as a `dynamicpb.Message`, with custom options
+// resolved from the extensions defined in the request rather than from `protoregistry.GlobalTypes`. Custom option values
+// can be read via the message's `Range` or `Get` methods.
+
+// GetDynamicOptions returns the file options
+func (f *PKFileDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return f.dynamicOptions(f.ProtoDesc().GetOptions())
+}
+
+// GetDynamicOptions returns the message options
+func (m *PKDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return m.GetFile().dynamicOptions(m.ProtoDesc().GetOptions())
+}
+
+// GetDynamicOptions returns the field options
+func (mf *PKFieldDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return mf.GetFile().dynamicOptions(mf.ProtoDesc().GetOptions())
+}
+
+// GetDynamicOptions returns the enum options
+func (e *PKEnumDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return e.GetFile().dynamicOptions(e.ProtoDesc().GetOptions())
+}
+
+// GetDynamicOptions returns the enum value options
+func (v *PKEnumValueDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return v.GetFile().dynamicOptions(v.ProtoDesc().GetOptions())
+}
+
+// GetDynamicOptions returns the field options of the extension
+func (e *PKExtensionDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return e.GetFile().dynamicOptions(e.ProtoDesc().GetOptions())
+}
+
+// GetDynamicOptions returns the service options
+func (s *PKServiceDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return s.GetFile().dynamicOptions(s.ProtoDesc().GetOptions())
+}
+
+// GetDynamicOptions returns the method options
+func (m *PKMethodDescriptor) GetDynamicOptions() *dynamicpb.Message {
+	return m.GetFile().dynamicOptions(m.ProtoDesc().GetOptions())
+}
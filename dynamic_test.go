@@ -0,0 +1,57 @@
+package protokit
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// dynamicOption returns the value of the custom option with the specified full name (nil if it isn't set)
+func dynamicOption(t *testing.T, opts protoreflect.Message, name protoreflect.FullName) protoreflect.Value {
+	t.Helper()
+
+	var value protoreflect.Value
+	opts.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.FullName() == name {
+			value = v
+			return false
+		}
+		return true
+	})
+
+	return value
+}
+
+func TestGetDynamicOptions(t *testing.T) {
+	files := parseFixture(t, "dynamic", DisableExtensionRegistration())
+	f := files["dyn/user.proto"]
+
+	if _, err := protoregistry.GlobalTypes.FindExtensionByName("dyn.owner"); err == nil {
+		t.Fatal("dyn.owner is registered with the Go registry")
+	}
+	if _, ok := f.GetFileOption("dyn.owner"); ok {
+		t.Error(`GetFileOption("dyn.owner") found an option whose extension isn't registered`)
+	}
+
+	opts := f.GetDynamicOptions()
+	if opts == nil {
+		t.Fatal("GetDynamicOptions() = nil")
+	}
+	if got := dynamicOption(t, opts, "dyn.owner"); !got.IsValid() || got.String() != "team-b" {
+		t.Errorf("(dyn.owner) = %v, want team-b", got)
+	}
+
+	limits := dynamicOption(t, f.GetMessage("Job").GetDynamicOptions(), "dyn.limits")
+	if !limits.IsValid() {
+		t.Fatal("(dyn.limits) isn't set")
+	}
+	max := limits.Message().Get(limits.Message().Descriptor().Fields().ByName("max"))
+	if got := max.Int(); got != 3 {
+		t.Errorf("(dyn.limits).max = %d, want 3", got)
+	}
+
+	if got := files["dyn/options.proto"].GetMessage("Limits").GetDynamicOptions(); got != nil {
+		t.Errorf("Limits.GetDynamicOptions() = %v, want nil as it has no options", got)
+	}
+}
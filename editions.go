@@ -0,0 +1,263 @@
+package protokit
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// editionDefaults returns the default feature values for the specified edition
+func editionDefaults(edition descriptorpb.Edition) *descriptorpb.FeatureSet {
+	switch {
+	case edition >= descriptorpb.Edition_EDITION_2023:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_EXPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_OPEN.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_PACKED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_VERIFY.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_ALLOW.Enum(),
+		}
+	case edition == descriptorpb.Edition_EDITION_PROTO3:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_IMPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_OPEN.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_PACKED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_VERIFY.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_ALLOW.Enum(),
+		}
+	default:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_EXPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_CLOSED.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_EXPANDED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_NONE.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_LEGACY_BEST_EFFORT.Enum(),
+		}
+	}
+}
+
+// resolveFeatures returns the defaults of the edition overridden by the feature sets of the scopes, which are ordered from
+// the most to the least specific (nil feature sets are skipped)
+func resolveFeatures(edition descriptorpb.Edition, scopes []*descriptorpb.FeatureSet) *descriptorpb.FeatureSet {
+	features := editionDefaults(edition)
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if scopes[i] != nil {
+			proto.Merge(features, scopes[i])
+		}
+	}
+
+	return features
+}
+
+// messageFeatureScopes returns the feature sets of the message and its parents, from the most to the least specific
+func messageFeatureScopes(m *PKDescriptor) []*descriptorpb.FeatureSet {
+	scopes := make([]*descriptorpb.FeatureSet, 0)
+	for ; m != nil; m = m.GetParent() {
+		scopes = append(scopes, m.ProtoDesc().GetOptions().GetFeatures())
+	}
+
+	return scopes
+}
+
+// legacyFieldFeatures returns the features implied by the label, type and options of a field in a proto2 or proto3 file,
+// which has no feature sets of its own (returns `nil` for files using editions)
+func legacyFieldFeatures(file *PKFileDescriptor, fd *descriptorpb.FieldDescriptorProto) *descriptorpb.FeatureSet {
+	if file.IsEditions() {
+		return nil
+	}
+
+	features := new(descriptorpb.FeatureSet)
+	switch {
+	case fd.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED:
+		features.FieldPresence = descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum()
+	case fd.GetProto3Optional():
+		features.FieldPresence = descriptorpb.FeatureSet_EXPLICIT.Enum()
+	}
+	if fd.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+		features.MessageEncoding = descriptorpb.FeatureSet_DELIMITED.Enum()
+	}
+	if fd.GetOptions() != nil && fd.GetOptions().Packed != nil {
+		features.RepeatedFieldEncoding = descriptorpb.FeatureSet_EXPANDED.Enum()
+		if fd.GetOptions().GetPacked() {
+			features.RepeatedFieldEncoding = descriptorpb.FeatureSet_PACKED.Enum()
+		}
+	}
+
+	return features
+}
+
+// featureScopes returns the feature sets that apply to the field, from the most to the least specific scope
+func (mf *PKFieldDescriptor) featureScopes() []*descriptorpb.FeatureSet {
+	scopes := []*descriptorpb.FeatureSet{
+		legacyFieldFeatures(mf.GetFile(), mf.ProtoDesc()),
+		mf.ProtoDesc().GetOptions().GetFeatures(),
+	}
+	if idx, ok := mf.GetOneofIndex(); ok && mf.GetMessage() != nil {
+		oneofs := mf.GetMessage().ProtoDesc().GetOneofDecl()
+		if int(idx) < len(oneofs) {
+			scopes = append(scopes, oneofs[idx].GetOptions().GetFeatures())
+		}
+	}
+	scopes = append(scopes, messageFeatureScopes(mf.GetMessage())...)
+
+	return append(scopes, mf.GetFile().ProtoDesc().GetOptions().GetFeatures())
+}
+
+// GetFeatures returns the resolved features of the file, i.e. the defaults of its edition overridden by its own feature
+// set. For proto2 and proto3 files, the defaults of the `EDITION_PROTO2` and `EDITION_PROTO3` editions are used.
+func (f *PKFileDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	return resolveFeatures(f.GetEdition(), []*descriptorpb.FeatureSet{f.ProtoDesc().GetOptions().GetFeatures()})
+}
+
+// GetFeatures returns the resolved features of the message, inherited from its parents and file (see
+// `PKFileDescriptor.GetFeatures`)
+func (m *PKDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	scopes := append(messageFeatureScopes(m), m.GetFile().ProtoDesc().GetOptions().GetFeatures())
+	return resolveFeatures(m.GetFile().GetEdition(), scopes)
+}
+
+// GetFeatures returns the resolved features of the field, inherited from its oneof, messages and file (see
+// `PKFileDescriptor.GetFeatures`). For proto2 and proto3 files, the features implied by the field's label, type and
+// `packed` option are applied (e.g. `LEGACY_REQUIRED` presence for `required` fields).
+func (mf *PKFieldDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	return resolveFeatures(mf.GetFile().GetEdition(), mf.featureScopes())
+}
+
+// GetFeatures returns the resolved features of the extension, inherited from the message it's declared in (if any) and
+// its file (see `PKFieldDescriptor.GetFeatures`)
+func (e *PKExtensionDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	scopes := []*descriptorpb.FeatureSet{
+		legacyFieldFeatures(e.GetFile(), e.ProtoDesc()),
+		e.ProtoDesc().GetOptions().GetFeatures(),
+	}
+	scopes = append(scopes, messageFeatureScopes(e.GetParent())...)
+	scopes = append(scopes, e.GetFile().ProtoDesc().GetOptions().GetFeatures())
+
+	return resolveFeatures(e.GetFile().GetEdition(), scopes)
+}
+
+// featureScopes returns the feature sets that apply to the enum, from the most to the least specific scope
+func (e *PKEnumDescriptor) featureScopes() []*descriptorpb.FeatureSet {
+	scopes := []*descriptorpb.FeatureSet{e.ProtoDesc().GetOptions().GetFeatures()}
+	scopes = append(scopes, messageFeatureScopes(e.GetParent())...)
+
+	return append(scopes, e.GetFile().ProtoDesc().GetOptions().GetFeatures())
+}
+
+// GetFeatures returns the resolved features of the enum, inherited from its parent messages and file (see
+// `PKFileDescriptor.GetFeatures`)
+func (e *PKEnumDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	return resolveFeatures(e.GetFile().GetEdition(), e.featureScopes())
+}
+
+// GetFeatures returns the resolved features of the enum value, inherited from its enum (see
+// `PKEnumDescriptor.GetFeatures`)
+func (v *PKEnumValueDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	scopes := append([]*descriptorpb.FeatureSet{v.ProtoDesc().GetOptions().GetFeatures()}, v.GetEnum().featureScopes()...)
+	return resolveFeatures(v.GetFile().GetEdition(), scopes)
+}
+
+// GetFeatures returns the resolved features of the service, inherited from its file (see `PKFileDescriptor.GetFeatures`)
+func (s *PKServiceDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	return resolveFeatures(s.GetFile().GetEdition(), []*descriptorpb.FeatureSet{
+		s.ProtoDesc().GetOptions().GetFeatures(),
+		s.GetFile().ProtoDesc().GetOptions().GetFeatures(),
+	})
+}
+
+// GetFeatures returns the resolved features of the method, inherited from its service and file (see
+// `PKFileDescriptor.GetFeatures`)
+func (m *PKMethodDescriptor) GetFeatures() *descriptorpb.FeatureSet {
+	return resolveFeatures(m.GetFile().GetEdition(), []*descriptorpb.FeatureSet{
+		m.ProtoDesc().GetOptions().GetFeatures(),
+		m.GetService().ProtoDesc().GetOptions().GetFeatures(),
+		m.GetFile().ProtoDesc().GetOptions().GetFeatures(),
+	})
+}
+
+// GetFieldPresence returns the `field_presence` feature of the field. For proto2 and proto3 files, the value is derived
+// from the field's label and the `optional` keyword.
+func (mf *PKFieldDescriptor) GetFieldPresence() descriptorpb.FeatureSet_FieldPresence {
+	return mf.GetFeatures().GetFieldPresence()
+}
+
+// HasExplicitPresence returns whether or not the field tracks presence (i.e. whether an unset field can be distinguished
+// from one set to its default value). This is determined uniformly across proto2, proto3 and editions: repeated fields
+// never track presence, message and oneof fields always do, and all other fields depend on `GetFieldPresence`.
+func (mf *PKFieldDescriptor) HasExplicitPresence() bool {
+	switch {
+	case mf.ProtoDesc().GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+		return false
+	case mf.ProtoDesc().GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		mf.ProtoDesc().GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return true
+	}
+	if _, inOneof := mf.GetOneofIndex(); inOneof {
+		return true
+	}
+
+	return mf.GetFieldPresence() != descriptorpb.FeatureSet_IMPLICIT
+}
+
+// Cardinality describes whether a field is optional, required or repeated
+type Cardinality int
+
+const (
+	// CardinalityOptional is used for singular fields that aren't required (whether or not they track presence)
+	CardinalityOptional Cardinality = iota
+	// CardinalityRequired is used for proto2 `required` fields and editions fields with `LEGACY_REQUIRED` presence
+	CardinalityRequired
+	// CardinalityRepeated is used for repeated and map fields
+	CardinalityRepeated
+)
+
+// String returns the lower-cased name of the cardinality
+func (c Cardinality) String() string {
+	switch c {
+	case CardinalityRequired:
+		return "required"
+	case CardinalityRepeated:
+		return "repeated"
+	default:
+		return "optional"
+	}
+}
+
+// GetCardinality returns the cardinality of the field, resolved uniformly from the field's label, the `optional` keyword
+// and the editions `field_presence` feature
+func (mf *PKFieldDescriptor) GetCardinality() Cardinality {
+	switch {
+	case mf.ProtoDesc().GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+		return CardinalityRepeated
+	case mf.GetFieldPresence() == descriptorpb.FeatureSet_LEGACY_REQUIRED:
+		return CardinalityRequired
+	default:
+		return CardinalityOptional
+	}
+}
+
+// IsPacked returns whether or not this is a repeated scalar field using the packed wire encoding
+func (mf *PKFieldDescriptor) IsPacked() bool {
+	if mf.ProtoDesc().GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return false
+	}
+
+	switch mf.ProtoDesc().GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+		descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	}
+
+	return mf.GetFeatures().GetRepeatedFieldEncoding() == descriptorpb.FeatureSet_PACKED
+}
+
+// IsClosed returns whether or not the enum is closed, i.e. whether unknown values are rejected rather than preserved.
+// Enums of proto2 files are closed and those of proto3 files are open, while under editions it's controlled by the
+// `enum_type` feature.
+func (e *PKEnumDescriptor) IsClosed() bool {
+	return e.GetFeatures().GetEnumType() == descriptorpb.FeatureSet_CLOSED
+}
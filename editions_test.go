@@ -0,0 +1,299 @@
+package protokit
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// parseSyntaxFixtures returns a proto2, a proto3 and an editions file by fixture name
+func parseSyntaxFixtures(t *testing.T) map[string]*PKFileDescriptor {
+	t.Helper()
+
+	return map[string]*PKFileDescriptor{
+		"legacy":   parseFixtureFile(t, "legacy", "legacy/legacy.proto"),
+		"shop":     parseFixtureFile(t, "shop", "shop/shop.proto"),
+		"editions": parseFixtureFile(t, "editions", "ed/ed.proto"),
+	}
+}
+
+func TestGetEdition(t *testing.T) {
+	tests := []struct {
+		fixture, file            string
+		edition                  descriptorpb.Edition
+		proto2, proto3, editions bool
+	}{
+		{fixture: "legacy", file: "legacy/legacy.proto", edition: descriptorpb.Edition_EDITION_PROTO2, proto2: true},
+		{fixture: "shop", file: "shop/shop.proto", edition: descriptorpb.Edition_EDITION_PROTO3, proto3: true},
+		{fixture: "editions", file: "ed/ed.proto", edition: descriptorpb.Edition_EDITION_2023, editions: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f := parseFixtureFile(t, tt.fixture, tt.file)
+			if got := f.GetEdition(); got != tt.edition {
+				t.Errorf("GetEdition() = %v, want %v", got, tt.edition)
+			}
+			if got := f.IsProto2(); got != tt.proto2 {
+				t.Errorf("IsProto2() = %v, want %v", got, tt.proto2)
+			}
+			if got := f.IsProto3(); got != tt.proto3 {
+				t.Errorf("IsProto3() = %v, want %v", got, tt.proto3)
+			}
+			if got := f.IsEditions(); got != tt.editions {
+				t.Errorf("IsEditions() = %v, want %v", got, tt.editions)
+			}
+		})
+	}
+}
+
+func TestGetLanguageVersion(t *testing.T) {
+	files := parseSyntaxFixtures(t)
+
+	tests := map[string]string{
+		"legacy":   "proto2",
+		"shop":     "proto3",
+		"editions": "editions/2023",
+	}
+	for fixture, want := range tests {
+		if got := files[fixture].GetLanguageVersion(); got != want {
+			t.Errorf("%s: GetLanguageVersion() = %q, want %q", files[fixture].GetName(), got, want)
+		}
+	}
+
+	// an empty syntax means proto2
+	f := &PKFileDescriptor{desc: new(descriptorpb.FileDescriptorProto)}
+	if got := f.GetLanguageVersion(); got != "proto2" {
+		t.Errorf("GetLanguageVersion() = %q without a syntax, want %q", got, "proto2")
+	}
+}
+
+func TestEditionsFieldFeatures(t *testing.T) {
+	files := parseSyntaxFixtures(t)
+
+	tests := []struct {
+		fixture, message, field string
+		presence                descriptorpb.FeatureSet_FieldPresence
+		packed                  bool
+	}{
+		{fixture: "legacy", message: "Order", field: "id", presence: descriptorpb.FeatureSet_LEGACY_REQUIRED},
+		{fixture: "legacy", message: "Order", field: "qty", presence: descriptorpb.FeatureSet_EXPLICIT},
+		{fixture: "legacy", message: "Order", field: "codes", presence: descriptorpb.FeatureSet_EXPLICIT},
+		{fixture: "legacy", message: "Order", field: "packed_codes", presence: descriptorpb.FeatureSet_EXPLICIT, packed: true},
+		{fixture: "shop", message: "Item", field: "item_id", presence: descriptorpb.FeatureSet_IMPLICIT},
+		{fixture: "shop", message: "Item", field: "limit", presence: descriptorpb.FeatureSet_EXPLICIT},
+		{fixture: "editions", message: "Msg", field: "a", presence: descriptorpb.FeatureSet_EXPLICIT},
+		{fixture: "editions", message: "Msg", field: "b", presence: descriptorpb.FeatureSet_IMPLICIT},
+		{fixture: "editions", message: "Msg", field: "c", presence: descriptorpb.FeatureSet_LEGACY_REQUIRED},
+		{fixture: "editions", message: "Msg", field: "packed", presence: descriptorpb.FeatureSet_EXPLICIT, packed: true},
+		{fixture: "editions", message: "Msg", field: "expanded", presence: descriptorpb.FeatureSet_EXPLICIT},
+		{fixture: "editions", message: "Plain", field: "x", presence: descriptorpb.FeatureSet_IMPLICIT},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message+"."+tt.field, func(t *testing.T) {
+			f := files[tt.fixture].GetMessage(tt.message).GetMessageField(tt.field)
+			if got := f.GetFieldPresence(); got != tt.presence {
+				t.Errorf("GetFieldPresence() = %v, want %v", got, tt.presence)
+			}
+			if got := f.IsPacked(); got != tt.packed {
+				t.Errorf("IsPacked() = %v, want %v", got, tt.packed)
+			}
+		})
+	}
+}
+
+func TestHasExplicitPresence(t *testing.T) {
+	files := parseSyntaxFixtures(t)
+
+	tests := []struct {
+		fixture, message, field string
+		explicit                bool
+	}{
+		{fixture: "legacy", message: "Order", field: "id", explicit: true},
+		{fixture: "legacy", message: "Order", field: "qty", explicit: true},
+		{fixture: "legacy", message: "Order", field: "codes"},
+		{fixture: "shop", message: "Item", field: "item_id"},
+		{fixture: "shop", message: "Item", field: "price", explicit: true},
+		{fixture: "shop", message: "Item", field: "name", explicit: true},
+		{fixture: "shop", message: "Item", field: "limit", explicit: true},
+		{fixture: "shop", message: "Item", field: "counts"},
+		{fixture: "editions", message: "Msg", field: "a", explicit: true},
+		{fixture: "editions", message: "Msg", field: "b"},
+		{fixture: "editions", message: "Msg", field: "c", explicit: true},
+		{fixture: "editions", message: "Msg", field: "plain", explicit: true},
+		{fixture: "editions", message: "Msg", field: "names"},
+		{fixture: "editions", message: "Plain", field: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+"/"+tt.message+"."+tt.field, func(t *testing.T) {
+			f := files[tt.fixture].GetMessage(tt.message).GetMessageField(tt.field)
+			if got := f.HasExplicitPresence(); got != tt.explicit {
+				t.Errorf("HasExplicitPresence() = %v, want %v", got, tt.explicit)
+			}
+		})
+	}
+}
+
+func TestHasOptionalKeyword(t *testing.T) {
+	files := parseSyntaxFixtures(t)
+
+	tests := []struct {
+		fixture, message, field string
+		keyword, presence       bool
+	}{
+		{fixture: "legacy", message: "Order", field: "id", presence: true},
+		{fixture: "legacy", message: "Order", field: "qty", keyword: true, presence: true},
+		{fixture: "legacy", message: "Order", field: "shipping", keyword: true, presence: true},
+		{fixture: "legacy", message: "Order", field: "codes"},
+		{fixture: "shop", message: "Item", field: "item_id"},
+		{fixture: "shop", message: "Item", field: "price", presence: true},
+		{fixture: "shop", message: "Item", field: "name", presence: true},
+		{fixture: "shop", message: "Item", field: "limit", keyword: true, presence: true},
+		{fixture: "editions", message: "Msg", field: "a", presence: true},
+		{fixture: "editions", message: "Msg", field: "plain", presence: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+"/"+tt.message+"."+tt.field, func(t *testing.T) {
+			f := files[tt.fixture].GetMessage(tt.message).GetMessageField(tt.field)
+			if got := f.HasOptionalKeyword(); got != tt.keyword {
+				t.Errorf("HasOptionalKeyword() = %v, want %v", got, tt.keyword)
+			}
+			if got := f.HasExplicitPresence(); got != tt.presence {
+				t.Errorf("HasExplicitPresence() = %v, want %v", got, tt.presence)
+			}
+		})
+	}
+}
+
+func TestGetCardinality(t *testing.T) {
+	files := parseSyntaxFixtures(t)
+
+	tests := []struct {
+		fixture, message, field string
+		want                    Cardinality
+	}{
+		{fixture: "legacy", message: "Order", field: "id", want: CardinalityRequired},
+		{fixture: "legacy", message: "Order", field: "qty", want: CardinalityOptional},
+		{fixture: "legacy", message: "Order", field: "codes", want: CardinalityRepeated},
+		{fixture: "shop", message: "Item", field: "item_id", want: CardinalityOptional},
+		{fixture: "shop", message: "Item", field: "limit", want: CardinalityOptional},
+		{fixture: "shop", message: "Item", field: "counts", want: CardinalityRepeated},
+		{fixture: "editions", message: "Msg", field: "a", want: CardinalityOptional},
+		{fixture: "editions", message: "Msg", field: "b", want: CardinalityOptional},
+		{fixture: "editions", message: "Msg", field: "c", want: CardinalityRequired},
+		{fixture: "editions", message: "Msg", field: "names", want: CardinalityRepeated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+"/"+tt.message+"."+tt.field, func(t *testing.T) {
+			f := files[tt.fixture].GetMessage(tt.message).GetMessageField(tt.field)
+			if got := f.GetCardinality(); got != tt.want {
+				t.Errorf("GetCardinality() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for c, want := range map[Cardinality]string{
+		CardinalityOptional: "optional", CardinalityRequired: "required", CardinalityRepeated: "repeated",
+	} {
+		if got := c.String(); got != want {
+			t.Errorf("Cardinality(%d).String() = %q, want %q", int(c), got, want)
+		}
+	}
+}
+
+func TestGetFeatures(t *testing.T) {
+	files := parseSyntaxFixtures(t)
+	ed := files["editions"]
+
+	type features interface {
+		GetFeatures() *descriptorpb.FeatureSet
+	}
+	tests := []struct {
+		name     string
+		desc     features
+		presence descriptorpb.FeatureSet_FieldPresence
+		enumType descriptorpb.FeatureSet_EnumType
+		encoding descriptorpb.FeatureSet_MessageEncoding
+	}{
+		// the file overrides the presence of the edition, and Msg overrides it again
+		{name: "ed.proto", desc: ed, presence: descriptorpb.FeatureSet_IMPLICIT, enumType: descriptorpb.FeatureSet_OPEN},
+		{name: "Msg", desc: ed.GetMessage("Msg"), presence: descriptorpb.FeatureSet_EXPLICIT,
+			enumType: descriptorpb.FeatureSet_OPEN},
+		{name: "Msg.a", desc: ed.GetMessage("Msg").GetMessageField("a"), presence: descriptorpb.FeatureSet_EXPLICIT,
+			enumType: descriptorpb.FeatureSet_OPEN},
+		{name: "Msg.b", desc: ed.GetMessage("Msg").GetMessageField("b"), presence: descriptorpb.FeatureSet_IMPLICIT,
+			enumType: descriptorpb.FeatureSet_OPEN},
+		{name: "Plain", desc: ed.GetMessage("Plain"), presence: descriptorpb.FeatureSet_IMPLICIT,
+			enumType: descriptorpb.FeatureSet_OPEN},
+		{name: "Closed", desc: ed.GetEnum("Closed"), presence: descriptorpb.FeatureSet_IMPLICIT,
+			enumType: descriptorpb.FeatureSet_CLOSED},
+		{name: "Closed.CLOSED_ONE", desc: ed.GetEnum("Closed").GetNamedValue("CLOSED_ONE"),
+			presence: descriptorpb.FeatureSet_IMPLICIT, enumType: descriptorpb.FeatureSet_CLOSED},
+		// proto2 and proto3 files use the defaults of their syntax, along with what the fields imply
+		{name: "legacy.proto", desc: files["legacy"], presence: descriptorpb.FeatureSet_EXPLICIT,
+			enumType: descriptorpb.FeatureSet_CLOSED},
+		{name: "Order.id", desc: files["legacy"].GetMessage("Order").GetMessageField("id"),
+			presence: descriptorpb.FeatureSet_LEGACY_REQUIRED, enumType: descriptorpb.FeatureSet_CLOSED},
+		{name: "Order.shipping", desc: files["legacy"].GetMessage("Order").GetMessageField("shipping"),
+			presence: descriptorpb.FeatureSet_EXPLICIT, enumType: descriptorpb.FeatureSet_CLOSED,
+			encoding: descriptorpb.FeatureSet_DELIMITED},
+		{name: "shop.proto", desc: files["shop"], presence: descriptorpb.FeatureSet_IMPLICIT,
+			enumType: descriptorpb.FeatureSet_OPEN},
+		{name: "Shop.GetItem", desc: files["shop"].GetService("Shop").GetNamedMethod("GetItem"),
+			presence: descriptorpb.FeatureSet_IMPLICIT, enumType: descriptorpb.FeatureSet_OPEN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := tt.desc.GetFeatures()
+			if got := fs.GetFieldPresence(); got != tt.presence {
+				t.Errorf("GetFieldPresence() = %v, want %v", got, tt.presence)
+			}
+			if got := fs.GetEnumType(); got != tt.enumType {
+				t.Errorf("GetEnumType() = %v, want %v", got, tt.enumType)
+			}
+			encoding := tt.encoding
+			if encoding == descriptorpb.FeatureSet_MESSAGE_ENCODING_UNKNOWN {
+				encoding = descriptorpb.FeatureSet_LENGTH_PREFIXED
+			}
+			if got := fs.GetMessageEncoding(); got != encoding {
+				t.Errorf("GetMessageEncoding() = %v, want %v", got, encoding)
+			}
+		})
+	}
+
+	// resolving the features leaves the feature sets of the descriptors untouched
+	ed.GetMessage("Msg").GetMessageField("a").GetFeatures().FieldPresence = descriptorpb.FeatureSet_IMPLICIT.Enum()
+	if got := ed.ProtoDesc().GetOptions().GetFeatures(); got.EnumType != nil || got.GetFieldPresence() != descriptorpb.FeatureSet_IMPLICIT {
+		t.Errorf("the file's feature set was modified: %v", got)
+	}
+	if got := ed.GetMessage("Msg").GetMessageField("a").GetFieldPresence(); got != descriptorpb.FeatureSet_EXPLICIT {
+		t.Errorf("Msg.a.GetFieldPresence() = %v after modifying the resolved features, want EXPLICIT", got)
+	}
+}
+
+func TestIsClosed(t *testing.T) {
+	files := parseSyntaxFixtures(t)
+
+	tests := []struct {
+		name   string
+		enum   *PKEnumDescriptor
+		closed bool
+	}{
+		{name: "proto2", enum: files["legacy"].GetEnum("Status"), closed: true},
+		{name: "proto3", enum: files["shop"].GetEnum("Color")},
+		{name: "proto3 nested", enum: files["shop"].GetMessage("Item").GetMessage("Detail").GetEnum("Level")},
+		{name: "editions default", enum: files["editions"].GetEnum("Open")},
+		{name: "editions override", enum: files["editions"].GetEnum("Closed"), closed: true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.enum.IsClosed(); got != tt.closed {
+			t.Errorf("%s: %s.IsClosed() = %t, want %t", tt.name, tt.enum.GetFullName(), got, tt.closed)
+		}
+	}
+}
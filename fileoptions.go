@@ -0,0 +1,44 @@
+package protokit
+
+import (
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// FileOptions holds the commonly used standard options of a file. Options that aren't set hold their default value.
+type FileOptions struct {
+	GoPackage            string
+	JavaPackage          string
+	JavaOuterClassname   string
+	JavaMultipleFiles    bool
+	OptimizeFor          descriptorpb.FileOptions_OptimizeMode
+	CcEnableArenas       bool
+	Deprecated           bool
+	ObjcClassPrefix      string
+	CsharpNamespace      string
+	SwiftPrefix          string
+	PhpNamespace         string
+	PhpMetadataNamespace string
+	RubyPackage          string
+}
+
+// GetFileOptions returns the commonly used standard options of the file, extracted from `ProtoDesc().GetOptions()`.
+// Custom options are available via `GetFileOption`.
+func (f *PKFileDescriptor) GetFileOptions() FileOptions {
+	opts := f.ProtoDesc().GetOptions()
+
+	return FileOptions{
+		GoPackage:            opts.GetGoPackage(),
+		JavaPackage:          opts.GetJavaPackage(),
+		JavaOuterClassname:   opts.GetJavaOuterClassname(),
+		JavaMultipleFiles:    opts.GetJavaMultipleFiles(),
+		OptimizeFor:          opts.GetOptimizeFor(),
+		CcEnableArenas:       opts.GetCcEnableArenas(),
+		Deprecated:           opts.GetDeprecated(),
+		ObjcClassPrefix:      opts.GetObjcClassPrefix(),
+		CsharpNamespace:      opts.GetCsharpNamespace(),
+		SwiftPrefix:          opts.GetSwiftPrefix(),
+		PhpNamespace:         opts.GetPhpNamespace(),
+		PhpMetadataNamespace: opts.GetPhpMetadataNamespace(),
+		RubyPackage:          opts.GetRubyPackage(),
+	}
+}
@@ -0,0 +1,54 @@
+package protokit
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGetFileOptions(t *testing.T) {
+	req := loadFixture(t, "shop", "shop/shop.proto")
+	for _, fd := range req.GetProtoFile() {
+		if fd.GetName() != "shop/shop.proto" {
+			continue
+		}
+
+		opts := fd.GetOptions()
+		opts.JavaPackage = proto.String("com.example.shop")
+		opts.JavaMultipleFiles = proto.Bool(true)
+		opts.OptimizeFor = descriptorpb.FileOptions_LITE_RUNTIME.Enum()
+		opts.CcEnableArenas = proto.Bool(false)
+		opts.Deprecated = proto.Bool(true)
+		opts.CsharpNamespace = proto.String("Example.Shop")
+	}
+
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]FileOptions{
+		"shop/shop.proto": {
+			GoPackage:         "example.com/gen/shop;shop",
+			JavaPackage:       "com.example.shop",
+			JavaMultipleFiles: true,
+			OptimizeFor:       descriptorpb.FileOptions_LITE_RUNTIME,
+			Deprecated:        true,
+			CsharpNamespace:   "Example.Shop",
+		},
+		// unset options hold their default value
+		"extra/weak.proto": {
+			GoPackage:      "example.com/gen/extra",
+			OptimizeFor:    descriptorpb.FileOptions_SPEED,
+			CcEnableArenas: true,
+		},
+	}
+	for _, f := range files {
+		if w, ok := want[f.GetName()]; ok {
+			if got := f.GetFileOptions(); got != w {
+				t.Errorf("%s: GetFileOptions() = %+v, want %+v", f.GetName(), got, w)
+			}
+		}
+	}
+}
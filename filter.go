@@ -0,0 +1,105 @@
+package protokit
+
+import (
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// FilterMessagesWithOption returns the messages of the file, including nested ones, whose options set the named extension
+// (e.g. `pkg.my_option`, with or without a leading "."). The messages are in the order they're visited by `Walk`.
+func FilterMessagesWithOption(file *PKFileDescriptor, extName string) []*PKDescriptor {
+	extName = strings.TrimPrefix(extName, ".")
+	msgs := make([]*PKDescriptor, 0)
+	Walk(file, func(d Descriptor) bool {
+		if msg, ok := d.(*PKDescriptor); ok && hasOptionExtension(msg.GetOptionExtensions(), extName) {
+			msgs = append(msgs, msg)
+		}
+
+		return true
+	})
+
+	return msgs
+}
+
+// FilterMethodsWithOption returns the methods of the service whose options set the named extension (e.g.
+// `pkg.my_option`, with or without a leading "."), in declaration order
+func FilterMethodsWithOption(service *PKServiceDescriptor, extName string) []*PKMethodDescriptor {
+	extName = strings.TrimPrefix(extName, ".")
+	methods := make([]*PKMethodDescriptor, 0)
+	for _, m := range service.GetMethods() {
+		if hasOptionExtension(m.GetOptionExtensions(), extName) {
+			methods = append(methods, m)
+		}
+	}
+
+	return methods
+}
+
+func hasOptionExtension(options map[string]interface{}, extName string) bool {
+	_, ok := options[extName]
+	return ok
+}
+
+// GetMessagesByOption returns the messages of the file, including nested ones, whose options set the named extension
+// (e.g. `pkg.my_option`, with or without a leading ".") to a value satisfying the predicate. The value passed to the
+// predicate is the one found in `GetOptionExtensions`. The messages are in the order they're visited by `Walk`.
+func (f *PKFileDescriptor) GetMessagesByOption(extName string, match func(interface{}) bool) []*PKDescriptor {
+	extName = strings.TrimPrefix(extName, ".")
+	msgs := make([]*PKDescriptor, 0)
+	for _, msg := range FilterMessagesWithOption(f, extName) {
+		if match(msg.GetOptionExtensions()[extName]) {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs
+}
+
+// UsedOptionExtensions returns the sorted, distinct full names of the option extensions set on the file or on any of its
+// descriptors (see `GetOptionExtensions`). Options left as unknown fields because their extension isn't registered (e.g.
+// with `DisableExtensionRegistration`) are included too, as they're resolved from the extensions defined in the request
+// (see `GetDynamicOptions`). Tooling can compare the names with the extensions it registers to detect options that would
+// otherwise go unresolved.
+func UsedOptionExtensions(file *PKFileDescriptor) []string {
+	seen := make(map[string]bool)
+	add := func(d dynamicOptionsDescriptor) {
+		for name := range d.GetOptionExtensions() {
+			seen[name] = true
+		}
+		if opts := d.GetDynamicOptions(); opts != nil {
+			opts.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+				if fd.IsExtension() {
+					seen[string(fd.FullName())] = true
+				}
+
+				return true
+			})
+		}
+	}
+
+	add(file)
+	Walk(file, func(d Descriptor) bool {
+		if d, ok := d.(dynamicOptionsDescriptor); ok {
+			add(d)
+		}
+
+		return true
+	})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// dynamicOptionsDescriptor is implemented by the file and by all the descriptors visited by `Walk`
+type dynamicOptionsDescriptor interface {
+	GetOptionExtensions() map[string]interface{}
+	GetDynamicOptions() *dynamicpb.Message
+}
@@ -0,0 +1,126 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFilterMessagesWithOption(t *testing.T) {
+	user := parseFixtureFile(t, "options", "user/user.proto")
+
+	tests := []struct {
+		ext  string
+		want []string
+	}{
+		{ext: "opts.resource", want: []string{"Account"}},
+		{ext: ".opts.meta", want: []string{"Account"}},
+		// set to false, but set nonetheless
+		{ext: "opts.table", want: []string{"Account", "Session"}},
+		{ext: "opts.sensitive", want: []string{}},
+		{ext: "opts.missing", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		got := make([]string, 0)
+		for _, m := range FilterMessagesWithOption(user, tt.ext) {
+			got = append(got, m.GetName())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FilterMessagesWithOption(%q) = %q, want %q", tt.ext, got, tt.want)
+		}
+	}
+}
+
+func TestFilterMethodsWithOption(t *testing.T) {
+	accounts := parseFixtureFile(t, "options", "user/user.proto").GetService("Accounts")
+
+	for _, ext := range []string{"opts.http_path", ".opts.http_path"} {
+		got := FilterMethodsWithOption(accounts, ext)
+		if len(got) != 1 || got[0].GetName() != "GetAccount" {
+			t.Errorf("FilterMethodsWithOption(%q) = %v, want [GetAccount]", ext, got)
+		}
+	}
+	if got := FilterMethodsWithOption(accounts, "opts.resource"); got == nil || len(got) != 0 {
+		t.Errorf(`FilterMethodsWithOption("opts.resource") = %v, want an empty slice`, got)
+	}
+}
+
+func TestGetMessagesByOption(t *testing.T) {
+	req := loadFixture(t, "options", "user/user.proto")
+	for _, fd := range req.GetProtoFile() {
+		if fd.GetName() != "user/user.proto" {
+			continue
+		}
+
+		// Session.Child gets the options of Account, opts.table = true among them
+		account, session := fd.GetMessageType()[0], fd.GetMessageType()[1]
+		session.NestedType = append(session.NestedType, &descriptorpb.DescriptorProto{
+			Name:    proto.String("Child"),
+			Options: proto.Clone(account.GetOptions()).(*descriptorpb.MessageOptions),
+		})
+	}
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var user *PKFileDescriptor
+	for _, f := range files {
+		if f.GetName() == "user/user.proto" {
+			user = f
+		}
+	}
+
+	isTrue := func(v interface{}) bool { b, ok := v.(bool); return ok && b }
+	tests := []struct {
+		ext   string
+		match func(interface{}) bool
+		want  []string
+	}{
+		{ext: "opts.table", match: isTrue, want: []string{"Account", "Session.Child"}},
+		{ext: ".opts.table", match: isTrue, want: []string{"Account", "Session.Child"}},
+		{ext: "opts.table", match: func(v interface{}) bool { return !isTrue(v) }, want: []string{"Session"}},
+		{ext: "opts.resource", match: func(v interface{}) bool { return v == "accounts" }, want: []string{"Account", "Session.Child"}},
+		{ext: "opts.missing", match: func(interface{}) bool { return true }, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		got := make([]string, 0)
+		for _, m := range user.GetMessagesByOption(tt.ext, tt.match) {
+			got = append(got, m.GetLongName())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetMessagesByOption(%q) = %q, want %q", tt.ext, got, tt.want)
+		}
+	}
+}
+
+func TestUsedOptionExtensions(t *testing.T) {
+	user := parseFixtureFile(t, "options", "user/user.proto")
+
+	// the uninterpreted unknown.opt isn't an extension
+	want := []string{
+		"opts.http_path", "opts.label", "opts.level", "opts.meta", "opts.policy", "opts.ratio", "opts.resource",
+		"opts.sensitive", "opts.strict", "opts.table",
+	}
+	if got := UsedOptionExtensions(user); !reflect.DeepEqual(got, want) {
+		t.Errorf("UsedOptionExtensions() = %q, want %q", got, want)
+	}
+
+	// the dyn options aren't registered, so they're left as unknown fields
+	job := parseFixtureFile(t, "dynamic", "dyn/user.proto", DisableExtensionRegistration())
+	if got := job.GetOptionExtensions(); len(got) != 0 {
+		t.Fatalf("GetOptionExtensions() = %v, want none", got)
+	}
+	want = []string{"dyn.limits", "dyn.owner"}
+	if got := UsedOptionExtensions(job); !reflect.DeepEqual(got, want) {
+		t.Errorf("UsedOptionExtensions() = %q, want %q", got, want)
+	}
+
+	if got := UsedOptionExtensions(parseFixtureFile(t, "tree", "tree/tree.proto")); got == nil || len(got) != 0 {
+		t.Errorf("UsedOptionExtensions() = %q, want an empty slice", got)
+	}
+}
@@ -0,0 +1,145 @@
+package protokit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	// link the well-known files imported by the fixtures into the Go registry
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// loadFixtureSet reads testdata/<name>.textproto, a FileDescriptorSet in text format. Imports of well-known files
+// missing from the set (e.g. `google/protobuf/descriptor.proto`) are added from the Go registry, and custom option values
+// are turned into unknown fields, as they are in the requests sent by protoc.
+func loadFixtureSet(t testing.TB, name string) *descriptorpb.FileDescriptorSet {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name+".textproto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the custom options of the set can only be read once the extensions it defines are known
+	set := new(descriptorpb.FileDescriptorSet)
+	opts := prototext.UnmarshalOptions{DiscardUnknown: true, Resolver: new(protoregistry.Types)}
+	if err := opts.Unmarshal(data, set); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	addWellKnownImports(t, set)
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	allFileDesc := make(map[string]protoreflect.FileDescriptor)
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		allFileDesc[fd.Path()] = fd
+		return true
+	})
+	types, err := newLocalTypes(allFileDesc)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	set = new(descriptorpb.FileDescriptorSet)
+	if err := (prototext.UnmarshalOptions{Resolver: types}).Unmarshal(data, set); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	addWellKnownImports(t, set)
+
+	wire, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set = new(descriptorpb.FileDescriptorSet)
+	if err := (proto.UnmarshalOptions{Resolver: new(protoregistry.Types)}).Unmarshal(wire, set); err != nil {
+		t.Fatal(err)
+	}
+
+	return set
+}
+
+// addWellKnownImports prepends the well-known files imported by the set but missing from it, so that the set stays in
+// dependency order
+func addWellKnownImports(t testing.TB, set *descriptorpb.FileDescriptorSet) {
+	t.Helper()
+
+	present := make(map[string]bool)
+	for _, f := range set.GetFile() {
+		present[f.GetName()] = true
+	}
+
+	var missing []*descriptorpb.FileDescriptorProto
+	var add func(path string)
+	add = func(path string) {
+		if present[path] {
+			return
+		}
+
+		fd, err := protoregistry.GlobalFiles.FindFileByPath(path)
+		if err != nil {
+			// e.g. a weak import left out on purpose
+			return
+		}
+		present[path] = true
+		for i := 0; i < fd.Imports().Len(); i++ {
+			add(fd.Imports().Get(i).Path())
+		}
+		missing = append(missing, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, f := range set.GetFile() {
+		for _, dep := range f.GetDependency() {
+			add(dep)
+		}
+	}
+
+	set.File = append(missing, set.File...)
+}
+
+// loadFixture returns the request protoc would send to generate the named files of the fixture
+func loadFixture(t testing.TB, name string, toGenerate ...string) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: toGenerate,
+		ProtoFile:      loadFixtureSet(t, name).GetFile(),
+	}
+}
+
+// parseFixture parses the fixture with `ParseCodeGenRequestAllFiles` and returns the parsed files by name
+func parseFixture(t testing.TB, name string, opts ...ParseOption) map[string]*PKFileDescriptor {
+	t.Helper()
+
+	files, err := ParseCodeGenRequestAllFiles(loadFixture(t, name), opts...)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	byName := make(map[string]*PKFileDescriptor, len(files))
+	for _, f := range files {
+		byName[f.GetName()] = f
+	}
+
+	return byName
+}
+
+// parseFixtureFile parses the fixture and returns the named file
+func parseFixtureFile(t testing.TB, name, file string, opts ...ParseOption) *PKFileDescriptor {
+	t.Helper()
+
+	f, ok := parseFixture(t, name, opts...)[file]
+	if !ok {
+		t.Fatalf("%s: file %q not found", name, file)
+	}
+
+	return f
+}
@@ -0,0 +1,46 @@
+package protokit
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// FlattenFields returns the dot-separated paths (e.g. `user.address.city`) of all fields within the message. Singular
+// message fields are descended into, while scalar, repeated and map fields are returned as-is. A message field whose
+// type is already being flattened (a recursive message, e.g. a tree node) is not descended into again, so the traversal
+// always terminates.
+//
+// maxDepth limits the number of path components (e.g. 2 stops at `user.address`); message fields at the limit are
+// returned as-is. A maxDepth of 0 or less doesn't limit the depth.
+func FlattenFields(msg *PKDescriptor, maxDepth int) []string {
+	if msg == nil {
+		return nil
+	}
+
+	return flattenFields(msg, "", 1, maxDepth, map[string]bool{msg.GetFullName(): true})
+}
+
+func flattenFields(msg *PKDescriptor, prefix string, depth, maxDepth int, visiting map[string]bool) []string {
+	paths := make([]string, 0, len(msg.GetMessageFields()))
+
+	for _, f := range msg.GetMessageFields() {
+		path := f.GetName()
+		if prefix != "" {
+			path = fmt.Sprintf("%s.%s", prefix, path)
+		}
+
+		typ := f.GetMessageType()
+		if typ == nil || f.ProtoDesc().GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED ||
+			visiting[typ.GetFullName()] || (maxDepth > 0 && depth >= maxDepth) {
+			paths = append(paths, path)
+			continue
+		}
+
+		visiting[typ.GetFullName()] = true
+		paths = append(paths, flattenFields(typ, path, depth+1, maxDepth, visiting)...)
+		delete(visiting, typ.GetFullName())
+	}
+
+	return paths
+}
@@ -0,0 +1,76 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetInputFieldPaths(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto").GetService("Shop")
+
+	want := []string{"item_id", "filter.query", "filter.page_size", "extra.note"}
+	if got := shop.GetNamedMethod("GetItem").GetInputFieldPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetInputFieldPaths() = %q, want %q", got, want)
+	}
+}
+
+func TestFlattenFields(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	tests := []struct {
+		maxDepth int
+		want     []string
+	}{
+		{
+			maxDepth: 0,
+			want: []string{
+				"item_id", "price.units", "price.currency_code", "tags", "counts", "name", "code", "limit",
+				"detail.level", "detail.note.text", "created_at.seconds", "created_at.nanos", "color", "prices",
+			},
+		},
+		{
+			maxDepth: 2,
+			want: []string{
+				"item_id", "price.units", "price.currency_code", "tags", "counts", "name", "code", "limit",
+				"detail.level", "detail.note", "created_at.seconds", "created_at.nanos", "color", "prices",
+			},
+		},
+		{
+			maxDepth: 1,
+			want: []string{
+				"item_id", "price", "tags", "counts", "name", "code", "limit", "detail", "created_at", "color", "prices",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := FlattenFields(item, tt.maxDepth); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FlattenFields(Item, %d) = %q, want %q", tt.maxDepth, got, tt.want)
+		}
+	}
+
+	if got := FlattenFields(nil, 0); got != nil {
+		t.Errorf("FlattenFields(nil, 0) = %q, want nil", got)
+	}
+}
+
+func TestFlattenFieldsRecursive(t *testing.T) {
+	f := parseFixtureFile(t, "tree", "tree/tree.proto")
+
+	tests := []struct {
+		msg      string
+		maxDepth int
+		want     []string
+	}{
+		// Node refers to itself directly, and through Edge.target
+		{msg: "Node", want: []string{"name", "parent", "children", "edge.target", "edge.weight"}},
+		{msg: "Node", maxDepth: 1, want: []string{"name", "parent", "children", "edge"}},
+		{msg: "Edge", want: []string{"target.name", "target.parent", "target.children", "target.edge", "weight"}},
+	}
+
+	for _, tt := range tests {
+		if got := FlattenFields(f.GetMessage(tt.msg), tt.maxDepth); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FlattenFields(%s, %d) = %q, want %q", tt.msg, tt.maxDepth, got, tt.want)
+		}
+	}
+}
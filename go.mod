@@ -2,4 +2,4 @@ module github.com/mingmxren/protokit
 
 go 1.18
 
-require google.golang.org/protobuf v1.31.0
+require google.golang.org/protobuf v1.33.0
@@ -0,0 +1,164 @@
+package protokit
+
+import (
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// GetGoImportPath returns the Go import path of the file, taken from the `go_package` option (e.g.
+// `example.com/foo;foopb` yields `example.com/foo`). Returns an empty string when the option isn't set
+func (f *PKFileDescriptor) GetGoImportPath() string {
+	goPkg := f.ProtoDesc().GetOptions().GetGoPackage()
+	if idx := strings.Index(goPkg, ";"); idx >= 0 {
+		goPkg = goPkg[:idx]
+	}
+
+	return goPkg
+}
+
+// GetTypeGoImportPath returns the Go import path of the file defining the field's message or enum type. Returns an empty
+// string for scalar fields and unresolved types
+func (mf *PKFieldDescriptor) GetTypeGoImportPath() string {
+	switch {
+	case mf.GetMessageType() != nil:
+		return mf.GetMessageType().GetFile().GetGoImportPath()
+	case mf.GetEnumType() != nil:
+		return mf.GetEnumType().GetFile().GetGoImportPath()
+	default:
+		return ""
+	}
+}
+
+// ComputeGoImports returns the sorted, distinct Go import paths of the types referenced by the file's fields (including
+// map values), extensions and methods, excluding the file's own Go package.
+func ComputeGoImports(file *PKFileDescriptor) []string {
+	paths := make(map[string]bool)
+	addMessage := func(m *PKDescriptor) {
+		if m != nil {
+			paths[m.GetFile().GetGoImportPath()] = true
+		}
+	}
+
+	var addFields func(fields []*PKFieldDescriptor)
+	addFields = func(fields []*PKFieldDescriptor) {
+		for _, f := range fields {
+			if f.IsMap() {
+				addFields([]*PKFieldDescriptor{f.GetMapValue()})
+				continue
+			}
+
+			paths[f.GetTypeGoImportPath()] = true
+		}
+	}
+
+	Walk(file, func(d Descriptor) bool {
+		switch desc := d.(type) {
+		case *PKDescriptor:
+			addFields(desc.GetMessageFields())
+		case *PKExtensionDescriptor:
+			addMessage(desc.GetExtendedMessage())
+		case *PKMethodDescriptor:
+			addMessage(desc.GetInputType())
+			addMessage(desc.GetOutputType())
+		}
+
+		return true
+	})
+
+	delete(paths, "")
+	delete(paths, file.GetGoImportPath())
+
+	imports := make([]string, 0, len(paths))
+	for path := range paths {
+		imports = append(imports, path)
+	}
+
+	sort.Strings(imports)
+	return imports
+}
+
+// GoZeroValue returns the Go literal of the zero value of the field as generated by protoc-gen-go: "nil" for repeated,
+// map, message, bytes, nullable (pointer) and oneof fields, "false" for bools, `""` for strings and "0" for numbers and
+// enums. Scalars with explicit presence (e.g. proto2 or proto3 `optional` fields) are pointers, while the members of a
+// oneof are held by the oneof's interface field, which is nil until one of them is set.
+func (mf *PKFieldDescriptor) GoZeroValue() string {
+	desc := mf.ProtoDesc()
+	switch {
+	case desc.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED,
+		desc.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		desc.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP,
+		desc.GetType() == descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return "nil"
+	case mf.HasExplicitPresence(), mf.InRealOneof():
+		return "nil"
+	case desc.GetType() == descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		return "false"
+	case desc.GetType() == descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return `""`
+	default:
+		return "0"
+	}
+}
+
+// GoName converts a proto name to a Go identifier the same way protoc-gen-go does: the first letter and any lower case
+// letter following an underscore or a dot are upper-cased (dropping the underscore or dot), a leading underscore becomes
+// an `X`, and the remaining dots become underscores. E.g. `foo_bar` and `fooBar` both become `FooBar`, `_foo` becomes
+// `XFoo` and `foo.Bar` becomes `Foo_Bar`
+func GoName(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// skip over '.' in ".{{lowercase}}"
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			// convert an initial '_' to ensure the identifier starts with a capital letter
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// skip over '_' in "_{{lowercase}}"
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			// the next word must start upper case, followed by any lower case letters
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+
+	return string(b)
+}
+
+// JSONCamelCase converts a proto field name to its default JSON name the same way protoc does: underscores are removed
+// and the letter following them is upper-cased. E.g. `foo_bar` becomes `fooBar`
+func JSONCamelCase(s string) string {
+	var b []byte
+	var wasUnderscore bool
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' {
+			if wasUnderscore && isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+		}
+		wasUnderscore = c == '_'
+	}
+
+	return string(b)
+}
+
+// GoName returns the name of the Go struct field generated for this field by protoc-gen-go
+func (mf *PKFieldDescriptor) GoName() string { return GoName(mf.GetName()) }
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
@@ -0,0 +1,158 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeGoImports(t *testing.T) {
+	files := parseFixture(t, "shop")
+
+	want := []string{
+		"example.com/gen/common",
+		"example.com/gen/extra",
+		"google.golang.org/protobuf/types/known/timestamppb",
+	}
+	if got := ComputeGoImports(files["shop/shop.proto"]); !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeGoImports(shop/shop.proto) = %q, want %q", got, want)
+	}
+
+	// the types of common/reexport.proto are all in its own Go package
+	if got := ComputeGoImports(files["common/reexport.proto"]); len(got) != 0 {
+		t.Errorf("ComputeGoImports(common/reexport.proto) = %q, want none", got)
+	}
+}
+
+func TestGetGoImportPath(t *testing.T) {
+	files := parseFixture(t, "shop")
+
+	tests := map[string]string{
+		"common/money.proto": "example.com/gen/common",
+		"extra/weak.proto":   "example.com/gen/extra",
+		"shop/shop.proto":    "example.com/gen/shop",
+	}
+	for name, want := range tests {
+		if got := files[name].GetGoImportPath(); got != want {
+			t.Errorf("%s: GetGoImportPath() = %q, want %q", name, got, want)
+		}
+	}
+
+	item := files["shop/shop.proto"].GetMessage("Item")
+	fields := map[string]string{
+		"price":   "example.com/gen/common",
+		"color":   "example.com/gen/shop",
+		"item_id": "",
+	}
+	for name, want := range fields {
+		if got := item.GetMessageField(name).GetTypeGoImportPath(); got != want {
+			t.Errorf("%s.GetTypeGoImportPath() = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGoZeroValue(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+	legacy := parseFixtureFile(t, "legacy", "legacy/legacy.proto").GetMessage("Order")
+	plain := parseFixtureFile(t, "editions", "ed/ed.proto").GetMessage("Plain")
+
+	tests := []struct {
+		field *PKFieldDescriptor
+		want  string
+	}{
+		{field: shop.GetMessageField("item_id"), want: `""`},
+		{field: shop.GetMessageField("tags"), want: "nil"},
+		{field: shop.GetMessageField("counts"), want: "nil"},
+		{field: shop.GetMessageField("name"), want: "nil"},  // oneof
+		{field: shop.GetMessageField("code"), want: "nil"},  // oneof
+		{field: shop.GetMessageField("limit"), want: "nil"}, // optional
+		{field: shop.GetMessageField("price"), want: "nil"},
+		{field: shop.GetMessageField("color"), want: "0"},
+		{field: legacy.GetMessageField("id"), want: "nil"}, // required
+		{field: legacy.GetMessageField("qty"), want: "nil"},
+		{field: legacy.GetMessageField("shipping"), want: "nil"},
+		{field: plain.GetMessageField("x"), want: "0"},
+		{field: plain.GetMessageField("flag"), want: "false"},
+		{field: plain.GetMessageField("data"), want: "nil"},
+		{field: plain.GetMessageField("text"), want: `""`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.field.GoZeroValue(); got != tt.want {
+			t.Errorf("%s.GoZeroValue() = %s, want %s", tt.field.GetLongName(), got, tt.want)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	// the cases of protoc-gen-go's own strs.GoCamelCase test
+	tests := []struct {
+		in, want string
+	}{
+		{in: "", want: ""},
+		{in: "one", want: "One"},
+		{in: "one_two", want: "OneTwo"},
+		{in: "_my_field_name_2", want: "XMyFieldName_2"},
+		{in: "Something_Capped", want: "Something_Capped"},
+		{in: "my_Name", want: "My_Name"},
+		{in: "OneTwo", want: "OneTwo"},
+		{in: "_", want: "X"},
+		{in: "_a_", want: "XA_"},
+		{in: "one.two", want: "OneTwo"},
+		{in: "one.Two", want: "One_Two"},
+		{in: "one_two.three_four", want: "OneTwoThreeFour"},
+		{in: "one_two.Three_four", want: "OneTwo_ThreeFour"},
+		{in: "_one._two", want: "XOne_XTwo"},
+		{in: "SCREAMING_SNAKE_CASE", want: "SCREAMING_SNAKE_CASE"},
+		{in: "double__underscore", want: "Double_Underscore"},
+		{in: "camelCase", want: "CamelCase"},
+		{in: "go2proto", want: "Go2Proto"},
+		{in: "2fast", want: "2Fast"},
+		{in: "世界", want: "世界"},
+		{in: "x世界", want: "X世界"},
+		{in: "foo_bar世界", want: "FooBar世界"},
+	}
+
+	for _, tt := range tests {
+		if got := GoName(tt.in); got != tt.want {
+			t.Errorf("GoName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+	if got := item.GetMessageField("created_at").GoName(); got != "CreatedAt" {
+		t.Errorf("created_at.GoName() = %q, want %q", got, "CreatedAt")
+	}
+}
+
+func TestJSONCamelCase(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "", want: ""},
+		{in: "foo", want: "foo"},
+		{in: "Foo", want: "Foo"},
+		{in: "foo_bar", want: "fooBar"},
+		{in: "fooBar", want: "fooBar"},
+		{in: "foo__bar", want: "fooBar"},
+		{in: "_foo", want: "Foo"},
+		{in: "foo_", want: "foo"},
+		{in: "foo_1bar", want: "foo1bar"},
+		{in: "FOO_BAR", want: "FOOBAR"},
+	}
+
+	for _, tt := range tests {
+		if got := JSONCamelCase(tt.in); got != tt.want {
+			t.Errorf("JSONCamelCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// protoc computes the same default json_name
+	Walk(parseFixtureFile(t, "shop", "shop/shop.proto"), func(d Descriptor) bool {
+		if f, ok := d.(*PKFieldDescriptor); ok {
+			if got, want := JSONCamelCase(f.GetName()), f.ProtoDesc().GetJsonName(); got != want {
+				t.Errorf("JSONCamelCase(%q) = %q, want the json_name %q", f.GetName(), got, want)
+			}
+		}
+		return true
+	})
+}
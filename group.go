@@ -0,0 +1,32 @@
+package protokit
+
+import (
+	"sort"
+)
+
+// GroupByPackage groups the files by their proto package. Within each package, files keep the order in which they were
+// supplied (e.g. sorted by name when coming from `ParseCodeGenRequestAllFiles`).
+func GroupByPackage(files []*PKFileDescriptor) map[string][]*PKFileDescriptor {
+	groups := make(map[string][]*PKFileDescriptor)
+	for _, f := range files {
+		groups[f.GetPackage()] = append(groups[f.GetPackage()], f)
+	}
+
+	return groups
+}
+
+// PackageNames returns the sorted, distinct package names of the files. It can be used to iterate the result of
+// `GroupByPackage` deterministically.
+func PackageNames(files []*PKFileDescriptor) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, f := range files {
+		if !seen[f.GetPackage()] {
+			seen[f.GetPackage()] = true
+			names = append(names, f.GetPackage())
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,41 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByPackage(t *testing.T) {
+	files, err := ParseCodeGenRequestAllFiles(loadFixture(t, "shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(files []*PKFileDescriptor) []string {
+		out := make([]string, 0, len(files))
+		for _, f := range files {
+			out = append(out, f.GetName())
+		}
+		return out
+	}
+
+	got := make(map[string][]string)
+	for pkg, group := range GroupByPackage(files) {
+		got[pkg] = names(group)
+	}
+
+	want := map[string][]string{
+		"common":          {"common/money.proto", "common/reexport.proto"},
+		"extra":           {"extra/weak.proto"},
+		"google.protobuf": {"google/protobuf/timestamp.proto"},
+		"shop":            {"shop/shop.proto"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByPackage() = %v, want %v", got, want)
+	}
+
+	wantNames := []string{"common", "extra", "google.protobuf", "shop"}
+	if got := PackageNames(files); !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("PackageNames() = %q, want %q", got, wantNames)
+	}
+}
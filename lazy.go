@@ -0,0 +1,155 @@
+package protokit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// LazyFiles holds the files of a request parsed with the `Lazy` option and parses them on demand. A file is parsed the first time it (or a file
+// importing it) is requested, so plugins that only touch the files to generate don't pay for building the
+// `PKFileDescriptor`s (comments, options and resolved types) of every other file in the request, nor for re-unmarshalling
+// their options. The request itself is still held in memory in full, and the `protoreflect` descriptors of all its files
+// are built up front to register their extensions.
+//
+// Since only the requested files and their imports are parsed, `UsedAsRequestBy` and `UsedAsResponseBy` only report
+// the methods of files that have been parsed so far. LazyFiles is safe for concurrent use.
+type LazyFiles struct {
+	mu          sync.Mutex
+	ctx         context.Context
+	protos      map[string]*descriptorpb.FileDescriptorProto
+	descs       map[string]protoreflect.FileDescriptor
+	files       map[string]*PKFileDescriptor
+	types       *protoregistry.Types
+	optionTypes *protoregistry.Types
+	version     *Version
+	names       []string
+	toGenerate  []string
+	isGenerated map[string]bool
+}
+
+// newLazyFiles prepares the files of the prepared request to be parsed on demand into files, with the context of the
+// parse. An error is returned if a file to generate is not one of the request's proto files.
+func newLazyFiles(ctx context.Context, req *pluginpb.CodeGeneratorRequest, descs map[string]protoreflect.FileDescriptor,
+	files map[string]*PKFileDescriptor, types *protoregistry.Types, options *parseOptions) (*LazyFiles, error) {
+	lazy := &LazyFiles{
+		ctx:         ctx,
+		protos:      make(map[string]*descriptorpb.FileDescriptorProto, len(req.GetProtoFile())),
+		descs:       descs,
+		files:       files,
+		types:       types,
+		optionTypes: options.optionTypes(),
+		version:     CompilerVersion(req),
+		names:       make([]string, 0, len(req.GetProtoFile())),
+		isGenerated: make(map[string]bool),
+	}
+
+	for _, pf := range req.GetProtoFile() {
+		lazy.protos[pf.GetName()] = pf
+		lazy.names = append(lazy.names, pf.GetName())
+	}
+	sort.Strings(lazy.names)
+
+	for _, name := range req.GetFileToGenerate() {
+		if _, ok := lazy.protos[name]; !ok {
+			return nil, fmt.Errorf("file to generate %q is not present in the request's proto files", name)
+		}
+		lazy.toGenerate = append(lazy.toGenerate, name)
+		lazy.isGenerated[name] = true
+	}
+
+	return lazy, nil
+}
+
+// GetNames returns the names of all the files in the request, sorted by name. Listing the names doesn't parse the files.
+func (l *LazyFiles) GetNames() []string { return l.names }
+
+// Get returns the named file, parsing it along with its imports if it hasn't been parsed yet. An error is returned if
+// the file is not part of the request.
+func (l *LazyFiles) Get(name string) (*PKFileDescriptor, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name = normalizeFileName(name)
+	if _, ok := l.protos[name]; !ok {
+		return nil, fmt.Errorf("file %q is not present in the request's proto files", name)
+	}
+
+	return l.load(name)
+}
+
+// GetFilesToGenerate returns the files to generate, in the order of the request, parsing them if needed.
+func (l *LazyFiles) GetFilesToGenerate() ([]*PKFileDescriptor, error) {
+	return l.loadAll(l.toGenerate)
+}
+
+// GetAll parses any remaining files and returns all the files of the request, sorted by name.
+func (l *LazyFiles) GetAll() ([]*PKFileDescriptor, error) {
+	return l.loadAll(l.names)
+}
+
+func (l *LazyFiles) loadAll(names []string) ([]*PKFileDescriptor, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files := make([]*PKFileDescriptor, len(names))
+	for i, name := range names {
+		f, err := l.load(name)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = f
+	}
+
+	return files, nil
+}
+
+// load parses the named file after its dependencies, so that the dependencies can be linked and their types resolved.
+// The options of the file are re-unmarshalled first so that they're populated with the request's extensions. The caller
+// must hold the lock and ensure the file is part of the request.
+func (l *LazyFiles) load(name string) (*PKFileDescriptor, error) {
+	if f, ok := l.files[name]; ok {
+		return f, nil
+	}
+
+	pf := l.protos[name]
+	for _, dep := range pf.GetDependency() {
+		// weak dependencies may be missing from the request
+		if _, ok := l.protos[dep]; ok {
+			if _, err := l.load(dep); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := reUnmarshalFile(pf, l.optionTypes); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	f := parseFile(l.ctx, pf, l.descs[name])
+	parseAllImports(f, l.files)
+	f.types = l.types
+	f.CompilerVersion = l.version
+	resolveTypes(f)
+	f.IsFileToGenerate = l.isGenerated[name]
+	l.files[name] = f
+
+	return f, nil
+}
+
+// reUnmarshalFile is the equivalent of `reUnmarshalReq` for a single file
+func reUnmarshalFile(pf *descriptorpb.FileDescriptorProto, resolver *protoregistry.Types) error {
+	data, err := proto.Marshal(pf)
+	if err != nil {
+		return err
+	}
+
+	return proto.UnmarshalOptions{Resolver: resolver}.Unmarshal(data, pf)
+}
@@ -0,0 +1,183 @@
+package protokit
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestLazy(t *testing.T) {
+	req := loadFixture(t, "options", "opts/options.proto")
+	var lazy *LazyFiles
+	files, err := ParseCodeGenRequestAllFiles(req, Lazy(&lazy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].GetName() != "opts/options.proto" || !files[0].IsFileToGenerate {
+		t.Fatalf("ParseCodeGenRequestAllFiles() = %v, want [opts/options.proto]", files)
+	}
+
+	want := []string{"google/protobuf/descriptor.proto", "opts/options.proto", "user/user.proto"}
+	if got := lazy.GetNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetNames() = %q, want %q", got, want)
+	}
+	if got, err := lazy.GetFilesToGenerate(); err != nil || len(got) != 1 || got[0] != files[0] {
+		t.Errorf("GetFilesToGenerate() = %v, %v, want the parsed opts/options.proto", got, err)
+	}
+
+	// user/user.proto hasn't been parsed yet, so its options are still unknown fields
+	var user *descriptorpb.FileDescriptorProto
+	for _, pf := range req.GetProtoFile() {
+		if pf.GetName() == "user/user.proto" {
+			user = pf
+		}
+	}
+	if len(user.GetOptions().ProtoReflect().GetUnknown()) == 0 {
+		t.Error("the options of user/user.proto were re-unmarshalled before the file was requested")
+	}
+
+	f, err := lazy.Get("user/user.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.IsFileToGenerate {
+		t.Error("user/user.proto is marked as a file to generate")
+	}
+	if got, ok := f.GetFileOptionString("opts.policy"); !ok || got != "strict" {
+		t.Errorf(`GetFileOptionString("opts.policy") = %q, %t, want "strict", true`, got, ok)
+	}
+	if got := f.GetDependencies(); len(got) != 1 || got[0] != files[0] {
+		t.Errorf("GetDependencies() = %v, want the parsed opts/options.proto", got)
+	}
+	if again, _ := lazy.Get("user/user.proto"); again != f {
+		t.Error("Get() parsed user/user.proto twice")
+	}
+
+	all, err := lazy.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(want) || all[2] != f {
+		t.Errorf("GetAll() = %v, want the %d files with the parsed user/user.proto", all, len(want))
+	}
+
+	if _, err := lazy.Get("missing.proto"); err == nil {
+		t.Error(`Get("missing.proto") succeeded, want an error`)
+	}
+
+	req = loadFixture(t, "options", "missing.proto")
+	if _, err := ParseCodeGenRequestAllFiles(req, Lazy(&lazy)); err == nil {
+		t.Error("ParseCodeGenRequestAllFiles() succeeded with a missing file to generate, want an error")
+	}
+}
+
+// newLargeRequest returns a request of n independent files with a few messages each, generating only the first file
+func newLargeRequest(n int) *pluginpb.CodeGeneratorRequest {
+	req := &pluginpb.CodeGeneratorRequest{FileToGenerate: []string{"bench/f0.proto"}}
+	for i := 0; i < n; i++ {
+		pf := &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(fmt.Sprintf("bench/f%d.proto", i)),
+			Package: proto.String(fmt.Sprintf("bench.f%d", i)),
+			Syntax:  proto.String("proto3"),
+		}
+		for j := 0; j < 10; j++ {
+			msg := &descriptorpb.DescriptorProto{Name: proto.String(fmt.Sprintf("M%d", j))}
+			for k := 1; k <= 10; k++ {
+				msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+					Name:     proto.String(fmt.Sprintf("f%d", k)),
+					JsonName: proto.String(fmt.Sprintf("f%d", k)),
+					Number:   proto.Int32(int32(k)),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				})
+			}
+			pf.MessageType = append(pf.MessageType, msg)
+		}
+		req.ProtoFile = append(req.ProtoFile, pf)
+	}
+
+	return req
+}
+
+// peakHeapInuse calls parse and returns how much the heap in use grew at its peak while parse ran and the result it
+// returned was still alive, sampling the heap every millisecond. The garbage collection preceding parse isn't timed.
+func peakHeapInuse(b *testing.B, parse func() interface{}) uint64 {
+	var ms runtime.MemStats
+	b.StopTimer()
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	base, peak := ms.HeapInuse, ms.HeapInuse
+	b.StartTimer()
+
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+
+		var ms runtime.MemStats
+		for {
+			runtime.ReadMemStats(&ms)
+			if ms.HeapInuse > peak {
+				peak = ms.HeapInuse
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	result := parse()
+	close(done)
+	<-sampled
+
+	runtime.ReadMemStats(&ms)
+	if ms.HeapInuse > peak {
+		peak = ms.HeapInuse
+	}
+	runtime.KeepAlive(result)
+
+	return peak - base
+}
+
+// BenchmarkLazyFiles compares the time and the peak heap in use of parsing all the files of a large request with
+// parsing only the file to generate with the `Lazy` option
+func BenchmarkLazyFiles(b *testing.B) {
+	orig := newLargeRequest(500)
+
+	bench := func(b *testing.B, opts ...ParseOption) {
+		b.ReportAllocs()
+		var peak uint64
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			req := proto.Clone(orig).(*pluginpb.CodeGeneratorRequest)
+			b.StartTimer()
+
+			peak += peakHeapInuse(b, func() interface{} {
+				files, err := ParseCodeGenRequestAllFiles(req, opts...)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				return files
+			})
+		}
+		b.ReportMetric(float64(peak)/float64(b.N), "peak-heap-B/op")
+	}
+
+	b.Run("eager", func(b *testing.B) { bench(b) })
+
+	b.Run("lazy", func(b *testing.B) {
+		var lazy *LazyFiles
+		bench(b, Lazy(&lazy))
+	})
+}
@@ -0,0 +1,178 @@
+package protokit
+
+import (
+	"sort"
+)
+
+// An EnumValueCollision describes an enum value name that is used by more than one enum of the same scope
+type EnumValueCollision struct {
+	Name   string
+	Values []*PKEnumValueDescriptor
+}
+
+// CollectEnumValueNames returns the values of every enum (top-level and nested) within the file, keyed by value name
+func CollectEnumValueNames(file *PKFileDescriptor) map[string][]*PKEnumValueDescriptor {
+	names := make(map[string][]*PKEnumValueDescriptor)
+
+	Walk(file, func(d Descriptor) bool {
+		if v, ok := d.(*PKEnumValueDescriptor); ok {
+			names[v.GetName()] = append(names[v.GetName()], v)
+		}
+
+		return true
+	})
+
+	return names
+}
+
+// FindEnumValueCollisions returns the enum value names of the file that are declared by more than one enum of the same
+// scope. Enum values follow the C++ scoping rules: they belong to the scope enclosing their enum rather than to the enum
+// itself, i.e. the package for top-level enums and the parent message for nested ones. Only enums declared in the same
+// scope (top-level enums, or sibling enums of a message) are compared, as values of nested enums don't clash with those
+// of other messages. protoc rejects such collisions, but descriptors built by other means may contain them. The result is
+// sorted by name, collisions of the same name in different scopes being in the order they're visited by `Walk`.
+func FindEnumValueCollisions(file *PKFileDescriptor) []*EnumValueCollision {
+	type scopedName struct {
+		scope *PKDescriptor
+		name  string
+	}
+
+	byName := make(map[scopedName]*EnumValueCollision)
+	enums := make(map[scopedName]map[*PKEnumDescriptor]bool)
+	order := make([]scopedName, 0)
+
+	Walk(file, func(d Descriptor) bool {
+		v, ok := d.(*PKEnumValueDescriptor)
+		if !ok {
+			return true
+		}
+
+		key := scopedName{scope: v.GetEnum().GetParent(), name: v.GetName()}
+		if _, ok := byName[key]; !ok {
+			byName[key] = &EnumValueCollision{Name: v.GetName()}
+			enums[key] = make(map[*PKEnumDescriptor]bool)
+			order = append(order, key)
+		}
+		byName[key].Values = append(byName[key].Values, v)
+		enums[key][v.GetEnum()] = true
+
+		return true
+	})
+
+	collisions := make([]*EnumValueCollision, 0)
+	for _, key := range order {
+		if len(enums[key]) > 1 {
+			collisions = append(collisions, byName[key])
+		}
+	}
+
+	sort.SliceStable(collisions, func(i, j int) bool {
+		return collisions[i].Name < collisions[j].Name
+	})
+
+	return collisions
+}
+
+// A NameCollision describes descriptors declared in the same scope whose names map to the same Go identifier once
+// camel-cased (e.g. the fields `foo_bar` and `fooBar` both become `FooBar`)
+type NameCollision struct {
+	GoName      string
+	Descriptors []Descriptor
+}
+
+// GetPaths returns the source paths (see `SourcePath`) of the colliding descriptors
+func (c *NameCollision) GetPaths() []string {
+	paths := make([]string, len(c.Descriptors))
+	for i, d := range c.Descriptors {
+		paths[i] = d.SourcePath()
+	}
+
+	return paths
+}
+
+// DetectNameCollisions returns the descriptors of the file that would generate colliding Go identifiers with
+// protoc-gen-go and protoc-gen-go-grpc. Names are compared within each Go scope:
+//
+//   - the package scope, which holds the types generated for all messages and enums (nested ones being prefixed with
+//     their parents' names, so the nested `Foo.Bar` collides with a top-level `Foo_Bar`), the wrapper types of oneof
+//     fields (the oneof field `bar` of `Foo` becomes `Foo_Bar`, colliding with a nested type `Foo.Bar`), and the
+//     `FooClient`, `FooServer` and `UnimplementedFooServer` types generated for a service `Foo`
+//   - the scope of each message, which holds the struct fields and getters of its fields
+//
+// Package scope collisions come first.
+func DetectNameCollisions(file *PKFileDescriptor) []*NameCollision {
+	pkgScope := make([]goIdent, 0)
+	fieldCollisions := make([]*NameCollision, 0)
+
+	Walk(file, func(d Descriptor) bool {
+		switch desc := d.(type) {
+		case *PKDescriptor:
+			pkgScope = append(pkgScope, goIdent{goTypeName(desc), desc})
+
+			fields := make([]goIdent, 0, len(desc.GetMessageFields()))
+			for _, f := range desc.GetMessageFields() {
+				fields = append(fields, goIdent{GoName(f.GetName()), f})
+				if f.InRealOneof() {
+					pkgScope = append(pkgScope, goIdent{goTypeName(desc) + "_" + GoName(f.GetName()), f})
+				}
+			}
+			fieldCollisions = append(fieldCollisions, findNameCollisions(fields)...)
+		case *PKEnumDescriptor:
+			pkgScope = append(pkgScope, goIdent{goTypeName(desc), desc})
+		case *PKServiceDescriptor:
+			name := GoName(desc.GetName())
+			pkgScope = append(pkgScope, goIdent{name + "Client", desc}, goIdent{name + "Server", desc},
+				goIdent{"Unimplemented" + name + "Server", desc})
+		}
+
+		return true
+	})
+
+	return append(findNameCollisions(pkgScope), fieldCollisions...)
+}
+
+// goIdent is a Go identifier generated for a descriptor
+type goIdent struct {
+	name string
+	desc Descriptor
+}
+
+// goTypeName returns the name of the Go type generated for the message or enum, the names of nested types being
+// prefixed with the names of their parents (e.g. `Foo_Bar`)
+func goTypeName(d Descriptor) string {
+	var parent *PKDescriptor
+	switch desc := d.(type) {
+	case *PKDescriptor:
+		parent = desc.GetParent()
+	case *PKEnumDescriptor:
+		parent = desc.GetParent()
+	}
+
+	if parent == nil {
+		return GoName(d.GetName())
+	}
+
+	return goTypeName(parent) + "_" + GoName(d.GetName())
+}
+
+func findNameCollisions(scope []goIdent) []*NameCollision {
+	byName := make(map[string]*NameCollision)
+	names := make([]string, 0)
+
+	for _, ident := range scope {
+		if _, ok := byName[ident.name]; !ok {
+			byName[ident.name] = &NameCollision{GoName: ident.name}
+			names = append(names, ident.name)
+		}
+		byName[ident.name].Descriptors = append(byName[ident.name].Descriptors, ident.desc)
+	}
+
+	collisions := make([]*NameCollision, 0)
+	for _, name := range names {
+		if len(byName[name].Descriptors) > 1 {
+			collisions = append(collisions, byName[name])
+		}
+	}
+
+	return collisions
+}
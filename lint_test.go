@@ -0,0 +1,85 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFindEnumValueCollisions(t *testing.T) {
+	f := parseFixtureFile(t, "lint", "lint/lint.proto")
+	if got := FindEnumValueCollisions(f); len(got) != 0 {
+		t.Fatalf("FindEnumValueCollisions() = %d collisions, want none", len(got))
+	}
+
+	// protoc rejects collisions within a scope, so they're introduced once parsed
+	f.GetEnum("Phase").GetNamedValue("PHASE_UNKNOWN").ProtoDesc().Name = proto.String("UNKNOWN")
+	f.GetMessage("Foo").GetEnum("Tier").GetNamedValue("TOP").ProtoDesc().Name = proto.String("HIGH")
+
+	type collision struct {
+		Name  string
+		Enums []string
+	}
+	got := make([]collision, 0)
+	for _, c := range FindEnumValueCollisions(f) {
+		enums := make([]string, 0, len(c.Values))
+		for _, v := range c.Values {
+			enums = append(enums, v.GetEnum().GetLongName())
+		}
+		got = append(got, collision{Name: c.Name, Enums: enums})
+	}
+
+	// UNKNOWN is also declared by Foo.Level and Foo_Bar.Kind, which are scoped to their messages
+	want := []collision{
+		{Name: "HIGH", Enums: []string{"Foo.Level", "Foo.Tier"}},
+		{Name: "UNKNOWN", Enums: []string{"Status", "Phase"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindEnumValueCollisions() = %+v, want %+v", got, want)
+	}
+
+	names := CollectEnumValueNames(f)
+	if got := len(names["ACTIVE"]); got != 1 {
+		t.Errorf("CollectEnumValueNames()[ACTIVE] = %d values, want 1", got)
+	}
+	if got := len(names["UNKNOWN"]); got != 4 {
+		t.Errorf("CollectEnumValueNames()[UNKNOWN] = %d values, want 4", got)
+	}
+}
+
+func TestDetectNameCollisions(t *testing.T) {
+	f := parseFixtureFile(t, "lint", "lint/lint.proto")
+
+	type collision struct {
+		GoName string
+		Names  []string
+	}
+	got := make([]collision, 0)
+	for _, c := range DetectNameCollisions(f) {
+		names := make([]string, 0, len(c.Descriptors))
+		for _, d := range c.Descriptors {
+			names = append(names, d.GetLongName())
+		}
+		got = append(got, collision{GoName: c.GoName, Names: names})
+	}
+
+	// the wrapper type of the oneof field `bar` collides with the nested message `Bar`, and the server interface of the
+	// service `Store` with the message `StoreServer`
+	want := []collision{
+		{GoName: "Foo_Bar", Names: []string{"Foo.bar", "Foo.Bar", "Foo_Bar"}},
+		{GoName: "StoreServer", Names: []string{"StoreServer", "Store"}},
+		{GoName: "FooBar", Names: []string{"Foo.foo_bar", "Foo.fooBar"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectNameCollisions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDetectNameCollisionsNone(t *testing.T) {
+	for name, f := range parseFixture(t, "shop") {
+		if got := DetectNameCollisions(f); len(got) != 0 {
+			t.Errorf("DetectNameCollisions(%s) = %d collisions, want none", name, len(got))
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package protokit
+
+import (
+	"strings"
+)
+
+// A Marker describes a maintenance marker (e.g. `TODO` or `FIXME`) found in the comments of a descriptor
+type Marker struct {
+	Descriptor Descriptor
+	Marker     string
+	Line       string
+}
+
+// CollectMarkers returns every comment line within the file containing one of the specified markers, along with the
+// descriptor the comment belongs to. Both leading and trailing comments are searched.
+func CollectMarkers(file *PKFileDescriptor, markers []string) []*Marker {
+	found := make([]*Marker, 0)
+
+	Walk(file, func(d Descriptor) bool {
+		for _, text := range []string{d.GetComments().GetLeading(), d.GetComments().GetTrailing()} {
+			for _, line := range strings.Split(text, "\n") {
+				for _, marker := range markers {
+					if strings.Contains(line, marker) {
+						found = append(found, &Marker{Descriptor: d, Marker: marker, Line: strings.TrimSpace(line)})
+						break
+					}
+				}
+			}
+		}
+
+		return true
+	})
+
+	return found
+}
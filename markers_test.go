@@ -0,0 +1,29 @@
+package protokit
+
+import (
+	"testing"
+)
+
+func TestCollectMarkers(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+
+	markers := CollectMarkers(f, []string{"FIXME", "TODO"})
+	if len(markers) != 1 {
+		t.Fatalf("CollectMarkers() = %d markers, want 1", len(markers))
+	}
+
+	m := markers[0]
+	if got, want := m.Descriptor.GetFullName(), ".shop.Item.tags"; got != want {
+		t.Errorf("Descriptor = %q, want %q", got, want)
+	}
+	if m.Marker != "TODO" {
+		t.Errorf("Marker = %q, want %q", m.Marker, "TODO")
+	}
+	if m.Line != "TODO: rename" {
+		t.Errorf("Line = %q, want %q", m.Line, "TODO: rename")
+	}
+
+	if got := CollectMarkers(f, []string{"XXX"}); len(got) != 0 {
+		t.Errorf("CollectMarkers(XXX) = %d markers, want none", len(got))
+	}
+}
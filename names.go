@@ -0,0 +1,48 @@
+package protokit
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// A NameResolver computes the long names and the SourceCodeInfo comment paths of the descriptors while a file is parsed.
+// The full names of the descriptors are derived from their long names and the file's package, and the comment paths are
+// used to look up their comments (see `ParseComments`), so a custom resolver affects type resolution and comments too.
+type NameResolver interface {
+	// LongName returns the long name of the descriptor with the specified name. The parent's long name is empty for
+	// top-level messages, enums and services.
+	LongName(file *PKFileDescriptor, parentLongName, name string) string
+
+	// ExtensionLongName returns the long name of the extension (see `PKExtensionDescriptor` for the default naming)
+	ExtensionLongName(file *PKFileDescriptor, ext *descriptorpb.FieldDescriptorProto) string
+
+	// CommentPath returns the path of the element at the index of the parent's repeated field with the specified tag
+	// number (e.g. the `4` of `message_type`). The parent's path is empty for the elements of the file itself.
+	CommentPath(parentPath string, tag int32, index int) string
+}
+
+// defaultNameResolver is the `NameResolver` used unless one is supplied with `WithNameResolver`. Long names are made of
+// the parents' names and the descriptor's name joined by a "." character (e.g. `Outer.Inner.field`), except for
+// extensions which use the extendee's name (e.g. `Msg.ext`). Paths are joined the same way (e.g. `4.0.2.1`).
+type defaultNameResolver struct{}
+
+func (defaultNameResolver) LongName(_ *PKFileDescriptor, parentLongName, name string) string {
+	if parentLongName == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s.%s", parentLongName, name)
+}
+
+func (defaultNameResolver) ExtensionLongName(file *PKFileDescriptor, ext *descriptorpb.FieldDescriptorProto) string {
+	return fmt.Sprintf("%s.%s", extendeeName(file, ext.GetExtendee()), ext.GetName())
+}
+
+func (defaultNameResolver) CommentPath(parentPath string, tag int32, index int) string {
+	if parentPath == "" {
+		return fmt.Sprintf("%d.%d", tag, index)
+	}
+
+	return fmt.Sprintf("%s.%d.%d", parentPath, tag, index)
+}
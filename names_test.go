@@ -0,0 +1,61 @@
+package protokit
+
+import (
+	"strings"
+	"testing"
+)
+
+// upperNames uppercases the long names, keeping the default extension names and comment paths
+type upperNames struct{ defaultNameResolver }
+
+func (upperNames) LongName(_ *PKFileDescriptor, parentLongName, name string) string {
+	return defaultNameResolver{}.LongName(nil, parentLongName, strings.ToUpper(name))
+}
+
+func TestWithNameResolver(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto", WithNameResolver(upperNames{}))
+
+	item := f.GetMessage("ITEM")
+	if item == nil {
+		t.Fatal(`GetMessage("ITEM") = nil`)
+	}
+	if got := item.GetFullName(); got != ".shop.ITEM" {
+		t.Errorf("Item.GetFullName() = %q, want .shop.ITEM", got)
+	}
+	if got := item.GetMessageField("item_id").GetLongName(); got != "ITEM.ITEM_ID" {
+		t.Errorf("item_id.GetLongName() = %q, want ITEM.ITEM_ID", got)
+	}
+	if got := item.GetMessage("Detail").GetMessage("Note").GetLongName(); got != "ITEM.DETAIL.NOTE" {
+		t.Errorf("Note.GetLongName() = %q, want ITEM.DETAIL.NOTE", got)
+	}
+	if got := f.GetService("SHOP").GetNamedMethod("GetItem").GetLongName(); got != "SHOP.GETITEM" {
+		t.Errorf("GetItem.GetLongName() = %q, want SHOP.GETITEM", got)
+	}
+
+	// the comment paths are left alone, so the comments are still found
+	if got := item.GetComments().GetLeading(); got != "An item for sale." {
+		t.Errorf("Item leading comment = %q, want %q", got, "An item for sale.")
+	}
+
+	// the default naming is unchanged
+	if got := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item").GetMessage("Detail").GetLongName(); got != "Item.Detail" {
+		t.Errorf("default Detail.GetLongName() = %q, want Item.Detail", got)
+	}
+}
+
+func TestDefaultNameResolver(t *testing.T) {
+	var names defaultNameResolver
+
+	if got := names.LongName(nil, "", "Item"); got != "Item" {
+		t.Errorf(`LongName("", "Item") = %q, want Item`, got)
+	}
+	if got := names.LongName(nil, "Item.Detail", "level"); got != "Item.Detail.level" {
+		t.Errorf(`LongName("Item.Detail", "level") = %q, want Item.Detail.level`, got)
+	}
+	if got := names.CommentPath("", messageCommentPath, 1); got != "4.1" {
+		t.Errorf(`CommentPath("", 4, 1) = %q, want 4.1`, got)
+	}
+	if got := names.CommentPath("4.1", messageFieldCommentPath, 0); got != "4.1.2.0" {
+		t.Errorf(`CommentPath("4.1", 2, 0) = %q, want 4.1.2.0`, got)
+	}
+}
@@ -0,0 +1,173 @@
+package protokit
+
+// The accessors of common return zero values on a nil receiver, but a method promoted through a nil descriptor panics
+// before it's reached, since Go has to take the address of the embedded common. Each descriptor type therefore forwards
+// the accessors to its common through base, which is the only nil check it needs.
+
+// base returns the common fields of the imported type (returns `nil` for a nil imported type)
+func (i *PKImportedDescriptor) base() *common {
+	if i == nil {
+		return nil
+	}
+
+	return &i.common
+}
+
+func (i *PKImportedDescriptor) GetFile() *PKFileDescriptor { return i.base().GetFile() }
+func (i *PKImportedDescriptor) GetPackage() string         { return i.base().GetPackage() }
+func (i *PKImportedDescriptor) GetLongName() string        { return i.base().GetLongName() }
+func (i *PKImportedDescriptor) GetFullName() string        { return i.base().GetFullName() }
+func (i *PKImportedDescriptor) GetIndex() int              { return i.base().GetIndex() }
+func (i *PKImportedDescriptor) IsProto3() bool             { return i.base().IsProto3() }
+func (i *PKImportedDescriptor) GetOptionExtensions() map[string]interface{} {
+	return i.base().GetOptionExtensions()
+}
+func (i *PKImportedDescriptor) GetPath() string    { return i.base().GetPath() }
+func (i *PKImportedDescriptor) SourcePath() string { return i.base().SourcePath() }
+
+// base returns the common fields of the message (returns `nil` for a nil message)
+func (m *PKDescriptor) base() *common {
+	if m == nil {
+		return nil
+	}
+
+	return &m.common
+}
+
+func (m *PKDescriptor) GetFile() *PKFileDescriptor { return m.base().GetFile() }
+func (m *PKDescriptor) GetPackage() string         { return m.base().GetPackage() }
+func (m *PKDescriptor) GetLongName() string        { return m.base().GetLongName() }
+func (m *PKDescriptor) GetFullName() string        { return m.base().GetFullName() }
+func (m *PKDescriptor) GetIndex() int              { return m.base().GetIndex() }
+func (m *PKDescriptor) IsProto3() bool             { return m.base().IsProto3() }
+func (m *PKDescriptor) GetOptionExtensions() map[string]interface{} {
+	return m.base().GetOptionExtensions()
+}
+func (m *PKDescriptor) GetPath() string    { return m.base().GetPath() }
+func (m *PKDescriptor) SourcePath() string { return m.base().SourcePath() }
+
+// base returns the common fields of the field (returns `nil` for a nil field)
+func (mf *PKFieldDescriptor) base() *common {
+	if mf == nil {
+		return nil
+	}
+
+	return &mf.common
+}
+
+func (mf *PKFieldDescriptor) GetFile() *PKFileDescriptor { return mf.base().GetFile() }
+func (mf *PKFieldDescriptor) GetPackage() string         { return mf.base().GetPackage() }
+func (mf *PKFieldDescriptor) GetLongName() string        { return mf.base().GetLongName() }
+func (mf *PKFieldDescriptor) GetFullName() string        { return mf.base().GetFullName() }
+func (mf *PKFieldDescriptor) GetIndex() int              { return mf.base().GetIndex() }
+func (mf *PKFieldDescriptor) IsProto3() bool             { return mf.base().IsProto3() }
+func (mf *PKFieldDescriptor) GetOptionExtensions() map[string]interface{} {
+	return mf.base().GetOptionExtensions()
+}
+func (mf *PKFieldDescriptor) GetPath() string    { return mf.base().GetPath() }
+func (mf *PKFieldDescriptor) SourcePath() string { return mf.base().SourcePath() }
+
+// base returns the common fields of the enum (returns `nil` for a nil enum)
+func (e *PKEnumDescriptor) base() *common {
+	if e == nil {
+		return nil
+	}
+
+	return &e.common
+}
+
+func (e *PKEnumDescriptor) GetFile() *PKFileDescriptor { return e.base().GetFile() }
+func (e *PKEnumDescriptor) GetPackage() string         { return e.base().GetPackage() }
+func (e *PKEnumDescriptor) GetLongName() string        { return e.base().GetLongName() }
+func (e *PKEnumDescriptor) GetFullName() string        { return e.base().GetFullName() }
+func (e *PKEnumDescriptor) GetIndex() int              { return e.base().GetIndex() }
+func (e *PKEnumDescriptor) IsProto3() bool             { return e.base().IsProto3() }
+func (e *PKEnumDescriptor) GetOptionExtensions() map[string]interface{} {
+	return e.base().GetOptionExtensions()
+}
+func (e *PKEnumDescriptor) GetPath() string    { return e.base().GetPath() }
+func (e *PKEnumDescriptor) SourcePath() string { return e.base().SourcePath() }
+
+// base returns the common fields of the enum value (returns `nil` for a nil enum value)
+func (v *PKEnumValueDescriptor) base() *common {
+	if v == nil {
+		return nil
+	}
+
+	return &v.common
+}
+
+func (v *PKEnumValueDescriptor) GetFile() *PKFileDescriptor { return v.base().GetFile() }
+func (v *PKEnumValueDescriptor) GetPackage() string         { return v.base().GetPackage() }
+func (v *PKEnumValueDescriptor) GetLongName() string        { return v.base().GetLongName() }
+func (v *PKEnumValueDescriptor) GetFullName() string        { return v.base().GetFullName() }
+func (v *PKEnumValueDescriptor) GetIndex() int              { return v.base().GetIndex() }
+func (v *PKEnumValueDescriptor) IsProto3() bool             { return v.base().IsProto3() }
+func (v *PKEnumValueDescriptor) GetOptionExtensions() map[string]interface{} {
+	return v.base().GetOptionExtensions()
+}
+func (v *PKEnumValueDescriptor) GetPath() string    { return v.base().GetPath() }
+func (v *PKEnumValueDescriptor) SourcePath() string { return v.base().SourcePath() }
+
+// base returns the common fields of the extension (returns `nil` for a nil extension)
+func (e *PKExtensionDescriptor) base() *common {
+	if e == nil {
+		return nil
+	}
+
+	return &e.common
+}
+
+func (e *PKExtensionDescriptor) GetFile() *PKFileDescriptor { return e.base().GetFile() }
+func (e *PKExtensionDescriptor) GetPackage() string         { return e.base().GetPackage() }
+func (e *PKExtensionDescriptor) GetLongName() string        { return e.base().GetLongName() }
+func (e *PKExtensionDescriptor) GetFullName() string        { return e.base().GetFullName() }
+func (e *PKExtensionDescriptor) GetIndex() int              { return e.base().GetIndex() }
+func (e *PKExtensionDescriptor) IsProto3() bool             { return e.base().IsProto3() }
+func (e *PKExtensionDescriptor) GetOptionExtensions() map[string]interface{} {
+	return e.base().GetOptionExtensions()
+}
+func (e *PKExtensionDescriptor) GetPath() string    { return e.base().GetPath() }
+func (e *PKExtensionDescriptor) SourcePath() string { return e.base().SourcePath() }
+
+// base returns the common fields of the service (returns `nil` for a nil service)
+func (s *PKServiceDescriptor) base() *common {
+	if s == nil {
+		return nil
+	}
+
+	return &s.common
+}
+
+func (s *PKServiceDescriptor) GetFile() *PKFileDescriptor { return s.base().GetFile() }
+func (s *PKServiceDescriptor) GetPackage() string         { return s.base().GetPackage() }
+func (s *PKServiceDescriptor) GetLongName() string        { return s.base().GetLongName() }
+func (s *PKServiceDescriptor) GetFullName() string        { return s.base().GetFullName() }
+func (s *PKServiceDescriptor) GetIndex() int              { return s.base().GetIndex() }
+func (s *PKServiceDescriptor) IsProto3() bool             { return s.base().IsProto3() }
+func (s *PKServiceDescriptor) GetOptionExtensions() map[string]interface{} {
+	return s.base().GetOptionExtensions()
+}
+func (s *PKServiceDescriptor) GetPath() string    { return s.base().GetPath() }
+func (s *PKServiceDescriptor) SourcePath() string { return s.base().SourcePath() }
+
+// base returns the common fields of the method (returns `nil` for a nil method)
+func (m *PKMethodDescriptor) base() *common {
+	if m == nil {
+		return nil
+	}
+
+	return &m.common
+}
+
+func (m *PKMethodDescriptor) GetFile() *PKFileDescriptor { return m.base().GetFile() }
+func (m *PKMethodDescriptor) GetPackage() string         { return m.base().GetPackage() }
+func (m *PKMethodDescriptor) GetLongName() string        { return m.base().GetLongName() }
+func (m *PKMethodDescriptor) GetFullName() string        { return m.base().GetFullName() }
+func (m *PKMethodDescriptor) GetIndex() int              { return m.base().GetIndex() }
+func (m *PKMethodDescriptor) IsProto3() bool             { return m.base().IsProto3() }
+func (m *PKMethodDescriptor) GetOptionExtensions() map[string]interface{} {
+	return m.base().GetOptionExtensions()
+}
+func (m *PKMethodDescriptor) GetPath() string    { return m.base().GetPath() }
+func (m *PKMethodDescriptor) SourcePath() string { return m.base().SourcePath() }
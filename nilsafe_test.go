@@ -0,0 +1,87 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNilReceivers(t *testing.T) {
+	nils := []interface{}{
+		(*PKFileDescriptor)(nil),
+		(*PKImportedDescriptor)(nil),
+		(*PKDescriptor)(nil),
+		(*PKFieldDescriptor)(nil),
+		(*PKEnumDescriptor)(nil),
+		(*PKEnumValueDescriptor)(nil),
+		(*PKExtensionDescriptor)(nil),
+		(*PKServiceDescriptor)(nil),
+		(*PKMethodDescriptor)(nil),
+	}
+
+	for _, n := range nils {
+		v := reflect.ValueOf(n)
+		for i := 0; i < v.NumMethod(); i++ {
+			method := v.Type().Method(i)
+			name := v.Type().Elem().Name() + "." + method.Name
+			t.Run(name, func(t *testing.T) {
+				// every argument is passed its zero value, e.g. an empty name
+				args := make([]reflect.Value, 0, method.Type.NumIn()-1)
+				for j := 1; j < method.Type.NumIn(); j++ {
+					args = append(args, reflect.Zero(method.Type.In(j)))
+				}
+
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("%s panicked on a nil receiver: %v", name, r)
+					}
+				}()
+				v.Method(i).Call(args)
+			})
+		}
+	}
+}
+
+func TestNilCommonAccessors(t *testing.T) {
+	type commonAccessors interface {
+		GetFile() *PKFileDescriptor
+		GetPackage() string
+		GetLongName() string
+		GetFullName() string
+		GetIndex() int
+		IsProto3() bool
+		GetOptionExtensions() map[string]interface{}
+		GetPath() string
+		SourcePath() string
+	}
+
+	nils := []commonAccessors{
+		(*PKImportedDescriptor)(nil),
+		(*PKDescriptor)(nil),
+		(*PKFieldDescriptor)(nil),
+		(*PKEnumDescriptor)(nil),
+		(*PKEnumValueDescriptor)(nil),
+		(*PKExtensionDescriptor)(nil),
+		(*PKServiceDescriptor)(nil),
+		(*PKMethodDescriptor)(nil),
+	}
+
+	for _, d := range nils {
+		if d.GetFile() != nil || d.GetPackage() != "" || d.GetLongName() != "" || d.GetFullName() != "" ||
+			d.GetIndex() != 0 || d.IsProto3() || d.GetOptionExtensions() != nil || d.GetPath() != "" ||
+			d.SourcePath() != "" {
+			t.Errorf("%T: the common accessors don't return zero values on a nil receiver", d)
+		}
+	}
+
+	// chained lookups of missing descriptors
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+	if got := f.GetMessage("Missing").GetMessageField("missing").GetFullName(); got != "" {
+		t.Errorf("GetFullName() of a missing field = %q, want empty", got)
+	}
+	if got := f.GetService("Missing").GetNamedMethod("Missing").SourcePath(); got != "" {
+		t.Errorf("SourcePath() of a missing method = %q, want empty", got)
+	}
+	if got := (*PKFileDescriptor)(nil).Clone(); got != nil {
+		t.Errorf("Clone() of a nil file = %v, want nil", got)
+	}
+}
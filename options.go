@@ -0,0 +1,108 @@
+package protokit
+
+import (
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// A ParseOption configures how `ParseCodeGenRequestAllFiles` parses a request
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	disableExtensionRegistration bool
+	includeMapEntries            bool
+	isolated                     bool
+	nameResolver                 NameResolver
+	commentTrimPolicy            CommentTrimPolicy
+	outputSort                   OutputSort
+	lazy                         **LazyFiles
+
+	// types is the local registry of the request's extensions, set once the request has been prepared
+	types *protoregistry.Types
+}
+
+func newParseOptions(opts []ParseOption) *parseOptions {
+	o := new(parseOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// DisableExtensionRegistration skips registering the extensions defined in the request with
+// `protoregistry.GlobalTypes`. Option extensions are then resolved using whatever is already registered, which avoids
+// duplicate registrations when the host has registered the extensions itself.
+func DisableExtensionRegistration() ParseOption {
+	return func(o *parseOptions) { o.disableExtensionRegistration = true }
+}
+
+// IncludeMapEntries keeps the synthetic map entry messages in the nested messages of a message (i.e. in
+// `PKDescriptor.GetMessages`), as they appear in the descriptor. This is useful for tooling reproducing descriptors
+// verbatim. The entries are available via `PKDescriptor.GetMapEntries` either way.
+func IncludeMapEntries() ParseOption {
+	return func(o *parseOptions) { o.includeMapEntries = true }
+}
+
+// Lazy parses only the files to generate and their imports rather than all the files of the request, which are then
+// returned in the order of the output sort, and stores in files the `LazyFiles` through which the other files are
+// parsed on demand. The context of the parse is kept to parse them.
+func Lazy(files **LazyFiles) ParseOption {
+	return func(o *parseOptions) { o.lazy = files }
+}
+
+// WithNameResolver computes the long names and comment paths of the descriptors with the resolver rather than the
+// default naming (see `NameResolver`)
+func WithNameResolver(resolver NameResolver) ParseOption {
+	return func(o *parseOptions) { o.nameResolver = resolver }
+}
+
+// WithCommentTrimPolicy normalizes the text of the comments according to the policy rather than with the default
+// `CommentClean` policy
+func WithCommentTrimPolicy(policy CommentTrimPolicy) ParseOption {
+	return func(o *parseOptions) { o.commentTrimPolicy = policy }
+}
+
+// OutputSort controls the order of the files returned by `ParseCodeGenRequestAllFiles`
+type OutputSort int
+
+const (
+	// SortAlphabetical sorts the files by name. This is the default.
+	SortAlphabetical OutputSort = iota
+	// SortAsRequested returns the files to generate first, in the order of the request's `FileToGenerate`, followed by
+	// the other files in the order of the request's proto files
+	SortAsRequested
+	// SortByDependency returns every file after the files it imports (see `SortFilesByDependency`)
+	SortByDependency
+)
+
+// WithOutputSort orders the returned files according to the sort rather than by name
+func WithOutputSort(sort OutputSort) ParseOption {
+	return func(o *parseOptions) { o.outputSort = sort }
+}
+
+// isolated resolves the option extensions using the local registry of the request's extensions only, leaving
+// `protoregistry.GlobalTypes` untouched (see `ParseIsolated`)
+func isolated() ParseOption {
+	return func(o *parseOptions) {
+		o.disableExtensionRegistration = true
+		o.isolated = true
+	}
+}
+
+// optionTypes returns the registry the option extensions are resolved from
+func (o *parseOptions) optionTypes() *protoregistry.Types {
+	if o.isolated {
+		return o.types
+	}
+
+	return protoregistry.GlobalTypes
+}
+
+// names returns the resolver computing the long names and comment paths of the descriptors
+func (o *parseOptions) names() NameResolver {
+	if o.nameResolver == nil {
+		return defaultNameResolver{}
+	}
+
+	return o.nameResolver
+}
@@ -0,0 +1,89 @@
+package protokit
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// optionsJSON renders the custom options (i.e. extensions) set on the options as a compact protojson object, keyed by
+// the bracketed full names of the extensions (e.g. `{"[my.pkg.policy]":"strict"}`). Standard options are left out, and
+// an empty object is returned when no custom options are set. The extensions are resolved from the request (see
+// `dynamicOptions`), so messages are rendered with their field names.
+func (f *PKFileDescriptor) optionsJSON(options proto.Message) (string, error) {
+	msg := f.dynamicOptions(options)
+	if msg == nil {
+		return "{}", nil
+	}
+
+	standard := make([]protoreflect.FieldDescriptor, 0)
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !fd.IsExtension() {
+			standard = append(standard, fd)
+		}
+
+		return true
+	})
+	for _, fd := range standard {
+		msg.Clear(fd)
+	}
+
+	data, err := protojson.MarshalOptions{Resolver: f.types}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	// protojson deliberately varies its whitespace, so the output is compacted to keep it stable
+	out := new(bytes.Buffer)
+	if err := json.Compact(out, data); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// The GetOptionsJSON accessors render the custom options of a descriptor as a protojson object, which is more readable
+// than formatting `GetOptionExtensions` with `%v` (e.g. in generated documentation).
+
+// GetOptionsJSON returns the custom file options as JSON
+func (f *PKFileDescriptor) GetOptionsJSON() (string, error) {
+	return f.optionsJSON(f.ProtoDesc().GetOptions())
+}
+
+// GetOptionsJSON returns the custom message options as JSON
+func (m *PKDescriptor) GetOptionsJSON() (string, error) {
+	return m.GetFile().optionsJSON(m.ProtoDesc().GetOptions())
+}
+
+// GetOptionsJSON returns the custom field options as JSON
+func (mf *PKFieldDescriptor) GetOptionsJSON() (string, error) {
+	return mf.GetFile().optionsJSON(mf.ProtoDesc().GetOptions())
+}
+
+// GetOptionsJSON returns the custom enum options as JSON
+func (e *PKEnumDescriptor) GetOptionsJSON() (string, error) {
+	return e.GetFile().optionsJSON(e.ProtoDesc().GetOptions())
+}
+
+// GetOptionsJSON returns the custom enum value options as JSON
+func (v *PKEnumValueDescriptor) GetOptionsJSON() (string, error) {
+	return v.GetFile().optionsJSON(v.ProtoDesc().GetOptions())
+}
+
+// GetOptionsJSON returns the custom field options of the extension as JSON
+func (e *PKExtensionDescriptor) GetOptionsJSON() (string, error) {
+	return e.GetFile().optionsJSON(e.ProtoDesc().GetOptions())
+}
+
+// GetOptionsJSON returns the custom service options as JSON
+func (s *PKServiceDescriptor) GetOptionsJSON() (string, error) {
+	return s.GetFile().optionsJSON(s.ProtoDesc().GetOptions())
+}
+
+// GetOptionsJSON returns the custom method options as JSON
+func (m *PKMethodDescriptor) GetOptionsJSON() (string, error) {
+	return m.GetFile().optionsJSON(m.ProtoDesc().GetOptions())
+}
@@ -0,0 +1,59 @@
+package protokit
+
+import (
+	"testing"
+)
+
+func TestGetOptionsJSON(t *testing.T) {
+	f := parseFixtureFile(t, "options", "user/user.proto")
+
+	tests := []struct {
+		name string
+		json func() (string, error)
+		want string
+	}{
+		{
+			// the extensions are sorted by name
+			name: "file",
+			json: f.GetOptionsJSON,
+			want: `{"[opts.level]":3,"[opts.policy]":"strict","[opts.ratio]":0.5,"[opts.strict]":true}`,
+		},
+		{
+			name: "message",
+			json: f.GetMessage("Account").GetOptionsJSON,
+			want: `{"[opts.meta]":{"owner":"team-a","tags":["billing"]},"[opts.resource]":"accounts","[opts.table]":true}`,
+		},
+		{
+			name: "field",
+			json: f.GetMessage("Account").GetMessageField("id").GetOptionsJSON,
+			want: `{"[opts.sensitive]":true}`,
+		},
+		{
+			// the standard deprecated option is left out
+			name: "enum value",
+			json: f.GetEnum("Role").GetNamedValue("ROLE_ADMIN").GetOptionsJSON,
+			want: `{"[opts.label]":"admin"}`,
+		},
+		{
+			name: "method",
+			json: f.GetService("Accounts").GetNamedMethod("GetAccount").GetOptionsJSON,
+			want: `{"[opts.http_path]":"/v1/accounts"}`,
+		},
+		{
+			name: "no options",
+			json: f.GetMessage("Plain").GetOptionsJSON,
+			want: `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.json()
+		if err != nil {
+			t.Errorf("%s: GetOptionsJSON() error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: GetOptionsJSON() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
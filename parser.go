@@ -3,7 +3,6 @@ package protokit
 import (
 	"context"
 	"fmt"
-	"log"
 	"sort"
 	"strings"
 
@@ -30,6 +29,7 @@ const (
 	messageMessageCommentPath   = 3 // nested_type
 	messageEnumCommentPath      = 4 // enum_type
 	messageExtensionCommentPath = 6 // extension
+	messageOneofCommentPath     = 8 // oneof_decl
 
 	// tag numbers in desc
 	enumValueCommentPath = 2 // value
@@ -38,7 +38,9 @@ const (
 	serviceMethodCommentPath = 2
 )
 
-func getAllFileDescriptor(req *pluginpb.CodeGeneratorRequest) map[string]protoreflect.FileDescriptor {
+// getAllFileDescriptor builds the descriptors of the request's files. An error is returned if the files are invalid,
+// e.g. if an import is missing from the request.
+func getAllFileDescriptor(req *pluginpb.CodeGeneratorRequest) (map[string]protoreflect.FileDescriptor, error) {
 	allFileDesc := make(map[string]protoreflect.FileDescriptor)
 	fileDescSet := &descriptorpb.FileDescriptorSet{}
 	for _, pf := range req.GetProtoFile() {
@@ -46,90 +48,326 @@ func getAllFileDescriptor(req *pluginpb.CodeGeneratorRequest) map[string]protore
 	}
 	files, err := protodesc.NewFiles(fileDescSet)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	// the registry already holds the descriptor of every file, so there's no need to build them again
 	for _, pf := range req.GetProtoFile() {
-		f, err := protodesc.NewFile(pf, files)
+		f, err := files.FindFileByPath(pf.GetName())
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		allFileDesc[pf.GetName()] = f
 	}
-	return allFileDesc
+	return allFileDesc, nil
 }
 
-func registerAllExtensions(allFileDesc map[string]protoreflect.FileDescriptor) {
+// registerAllExtensions registers the top-level extensions of the files with `protoregistry.GlobalTypes`. Extensions that
+// are already registered (e.g. because the same request is parsed twice, or the extension's Go type is linked in) are
+// left untouched, but an error is returned if a different extension uses the same number of the same message.
+func registerAllExtensions(allFileDesc map[string]protoreflect.FileDescriptor) error {
 	for _, fileDesc := range allFileDesc {
 		extensions := fileDesc.Extensions()
 		for i := 0; i < extensions.Len(); i++ {
 			ext := extensions.Get(i)
-			err := protoregistry.GlobalTypes.RegisterExtension(dynamicpb.NewExtensionType(ext))
+			registered, err := isExtensionRegistered(ext)
 			if err != nil {
-				log.Fatal(err)
+				return err
+			}
+			if registered {
+				continue
+			}
+
+			if err := protoregistry.GlobalTypes.RegisterExtension(dynamicpb.NewExtensionType(ext)); err != nil {
+				return err
 			}
 		}
+	}
+
+	return nil
+}
 
+// isExtensionRegistered returns whether or not an extension with the same full name is registered. An error is returned
+// if another extension is registered with the same number on the same message, which `protoregistry.GlobalTypes` would
+// panic on.
+func isExtensionRegistered(ext protoreflect.ExtensionDescriptor) (bool, error) {
+	if _, err := protoregistry.GlobalTypes.FindExtensionByName(ext.FullName()); err == nil {
+		return true, nil
 	}
+
+	other, err := protoregistry.GlobalTypes.FindExtensionByNumber(ext.ContainingMessage().FullName(), ext.Number())
+	if err == nil {
+		return false, fmt.Errorf("extension %s conflicts with the registered extension %s: both extend %s with number %d",
+			ext.FullName(), other.TypeDescriptor().FullName(), ext.ContainingMessage().FullName(), ext.Number())
+	}
+
+	return false, nil
 }
-func reUnmarshalReq(req *pluginpb.CodeGeneratorRequest) (err error) {
+
+// normalizeFileNames converts the file names of the request (the files, their dependencies and the files to generate)
+// to use forward slashes, so that requests produced on Windows can still be matched by name
+func normalizeFileNames(req *pluginpb.CodeGeneratorRequest) {
+	for i, name := range req.GetFileToGenerate() {
+		req.FileToGenerate[i] = normalizeFileName(name)
+	}
+
+	for _, pf := range req.GetProtoFile() {
+		pf.Name = proto.String(normalizeFileName(pf.GetName()))
+		for i, dep := range pf.GetDependency() {
+			pf.Dependency[i] = normalizeFileName(dep)
+		}
+	}
+}
+
+func normalizeFileName(name string) string {
+	return strings.ReplaceAll(name, "\\", "/")
+}
+
+func reUnmarshalReq(req *pluginpb.CodeGeneratorRequest, resolver *protoregistry.Types) (err error) {
 	reqData, err := proto.Marshal(req)
 	if err != nil {
 		return
 	}
-	err = proto.Unmarshal(reqData, req)
+	err = proto.UnmarshalOptions{Resolver: resolver}.Unmarshal(reqData, req)
 	if err != nil {
 		return
 	}
 	return
 }
 
-func ParseCodeGenRequestAllFiles(req *pluginpb.CodeGeneratorRequest) ([]*PKFileDescriptor, error) {
+// prepareRequest builds the file descriptors of the request and registers their extensions, then re-unmarshals the
+// request so that its options are populated with the extensions. It returns the file descriptors by name along with a
+// local registry of the request's types, which is also recorded in the options.
+func prepareRequest(req *pluginpb.CodeGeneratorRequest,
+	options *parseOptions) (map[string]protoreflect.FileDescriptor, *protoregistry.Types, error) {
+	allFileDesc, types, err := prepareFiles(req, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := reUnmarshalReq(req, options.optionTypes()); err != nil {
+		return nil, nil, err
+	}
+
+	return allFileDesc, types, nil
+}
+
+// prepareFiles is `prepareRequest` without re-unmarshalling the request, which is left to the caller (e.g. `LazyFiles`
+// re-unmarshals each file as it's parsed)
+func prepareFiles(req *pluginpb.CodeGeneratorRequest,
+	options *parseOptions) (map[string]protoreflect.FileDescriptor, *protoregistry.Types, error) {
+	normalizeFileNames(req)
+	allFileDesc, err := getAllFileDescriptor(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !options.disableExtensionRegistration {
+		if err := registerAllExtensions(allFileDesc); err != nil {
+			return nil, nil, err
+		}
+	}
+	types, err := newLocalTypes(allFileDesc)
+	if err != nil {
+		return nil, nil, err
+	}
+	options.types = types
+
+	return allFileDesc, types, nil
+}
+
+// ParseCodeGenRequestAllFiles parses all the files of the request (see `ParseCodeGenRequestAllFilesContext`)
+func ParseCodeGenRequestAllFiles(req *pluginpb.CodeGeneratorRequest, opts ...ParseOption) ([]*PKFileDescriptor, error) {
+	return ParseCodeGenRequestAllFilesContext(context.Background(), req, opts...)
+}
+
+// ParseIsolated parses all the files of the request (see `ParseCodeGenRequestAllFilesContext`) without touching
+// `protoregistry.GlobalTypes`: the extensions defined in the request aren't registered globally, and the option
+// extensions are resolved from a local registry instead, so their values are `dynamicpb` messages rather than generated
+// Go types. The local registry is returned so callers can keep resolving options with it. Since no global state is
+// involved, requests can be parsed concurrently.
+func ParseIsolated(req *pluginpb.CodeGeneratorRequest, opts ...ParseOption) ([]*PKFileDescriptor, *protoregistry.Types,
+	error) {
+	options := newParseOptions(append(opts, isolated()))
+	files, err := parseCodeGenRequest(context.Background(), req, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, options.types, nil
+}
+
+// ParseFileDescriptorSet parses all the files of the set (e.g. the output of `protoc --descriptor_set_out`), marking the
+// named files as files to generate. The set is parsed the same way as a request (see
+// `ParseCodeGenRequestAllFilesContext`), so its files must include all their imports, and an error is returned if they
+// don't. The set itself is left untouched: the parsed files hold a copy of its descriptor protos.
+func ParseFileDescriptorSet(set *descriptorpb.FileDescriptorSet, filesToGenerate []string,
+	opts ...ParseOption) ([]*PKFileDescriptor, error) {
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: append([]string(nil), filesToGenerate...),
+		ProtoFile:      proto.Clone(set).(*descriptorpb.FileDescriptorSet).GetFile(),
+	}
+
+	return ParseCodeGenRequestAllFiles(req, opts...)
+}
+
+// ParseFromFiles parses all the files of the registry, marking the named files as files to generate. The files are
+// converted back to descriptor protos in dependency order and parsed as a descriptor set (see `ParseFileDescriptorSet`).
+// An error is returned if a file of the registry is a placeholder, or imports one (i.e. the registry was built allowing
+// unresolvable imports), except for weak imports which may be missing.
+func ParseFromFiles(files *protoregistry.Files, toGenerate []string, opts ...ParseOption) ([]*PKFileDescriptor, error) {
+	all := make([]protoreflect.FileDescriptor, 0, files.NumFiles())
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		all = append(all, fd)
+		return true
+	})
+	sort.Slice(all, func(i, j int) bool { return all[i].Path() < all[j].Path() })
+
+	set := new(descriptorpb.FileDescriptorSet)
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor) error
+	add = func(fd protoreflect.FileDescriptor) error {
+		if seen[fd.Path()] {
+			return nil
+		}
+		if fd.IsPlaceholder() {
+			return fmt.Errorf("file %q is a placeholder", fd.Path())
+		}
+
+		seen[fd.Path()] = true
+		for i := 0; i < fd.Imports().Len(); i++ {
+			imp := fd.Imports().Get(i)
+			if imp.IsPlaceholder() {
+				if imp.IsWeak {
+					continue
+				}
+
+				return fmt.Errorf("file %q imports %q, which is missing from the registry", fd.Path(), imp.Path())
+			}
+			if err := add(imp.FileDescriptor); err != nil {
+				return err
+			}
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+
+		return nil
+	}
+	for _, fd := range all {
+		if err := add(fd); err != nil {
+			return nil, err
+		}
+	}
+
+	return ParseFileDescriptorSet(set, toGenerate, opts...)
+}
+
+// ParseCodeGenRequestAllFilesContext parses all the files of the request, sorted by name unless another order is
+// requested with `WithOutputSort`. The context is checked between file parses, and its error is returned if it was
+// cancelled or its deadline exceeded. An error is also returned if the request's proto files are invalid (e.g. an import
+// is missing from the request), or if a file to generate is not one of them.
+func ParseCodeGenRequestAllFilesContext(ctx context.Context, req *pluginpb.CodeGeneratorRequest,
+	opts ...ParseOption) ([]*PKFileDescriptor, error) {
+	return parseCodeGenRequest(ctx, req, newParseOptions(opts))
+}
+
+func parseCodeGenRequest(ctx context.Context, req *pluginpb.CodeGeneratorRequest,
+	options *parseOptions) ([]*PKFileDescriptor, error) {
 	allFilesMap := make(map[string]*PKFileDescriptor)
 	allFiles := make([]*PKFileDescriptor, 0, len(req.GetProtoFile()))
 
-	allFileDesc := getAllFileDescriptor(req)
-	registerAllExtensions(allFileDesc)
-	err := reUnmarshalReq(req)
+	prepare := prepareRequest
+	if options.lazy != nil {
+		// the files are re-unmarshalled as they're parsed
+		prepare = prepareFiles
+	}
+	allFileDesc, types, err := prepare(req, options)
 	if err != nil {
 		return nil, err
 	}
-	ctx := ContextWithAllFiles(context.Background(), allFilesMap)
+	ctx = contextWithParseOptions(ContextWithAllFiles(ctx, allFilesMap), options)
+
+	if options.lazy != nil {
+		lazy, err := newLazyFiles(ctx, req, allFileDesc, allFilesMap, types, options)
+		if err != nil {
+			return nil, err
+		}
+		*options.lazy = lazy
+
+		files, err := lazy.GetFilesToGenerate()
+		if err != nil {
+			return nil, err
+		}
+
+		return sortFiles(files, req, options.outputSort)
+	}
 
 	for _, pf := range req.GetProtoFile() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		allFilesMap[pf.GetName()] = parseFile(ctx, pf, allFileDesc[pf.GetName()])
 	}
 
-	for _, f := range allFilesMap {
+	// iterate the request rather than the map so that processing order doesn't depend on map iteration
+	for _, pf := range req.GetProtoFile() {
+		f := allFilesMap[pf.GetName()]
 		parseAllImports(f, allFilesMap)
 		allFiles = append(allFiles, f)
 	}
 
-	for _, f := range req.FileToGenerate {
+	version := CompilerVersion(req)
+	for _, f := range allFiles {
+		f.types = types
+		f.CompilerVersion = version
+		resolveTypes(f)
+	}
+
+	for _, name := range req.FileToGenerate {
 		// mark files to generate
-		allFilesMap[f].IsFileToGenerate = true
+		f, ok := allFilesMap[name]
+		if !ok {
+			return nil, fmt.Errorf("file to generate %q is not present in the request's proto files", name)
+		}
+		f.IsFileToGenerate = true
 	}
 
-	sort.Slice(allFiles, func(i, j int) bool {
-		return allFiles[i].GetName() < allFiles[j].GetName()
-	})
+	return sortFiles(allFiles, req, options.outputSort)
+}
 
-	return allFiles, nil
+// sortFiles orders the files of the request according to the sort
+func sortFiles(files []*PKFileDescriptor, req *pluginpb.CodeGeneratorRequest,
+	outputSort OutputSort) ([]*PKFileDescriptor, error) {
+	switch outputSort {
+	case SortAsRequested:
+		// files to generate get negative ranks in request order, and the other files keep the order of the request's
+		// proto files (which the files are in) thanks to the stable sort
+		rank := make(map[string]int, len(req.GetFileToGenerate()))
+		for i, name := range req.GetFileToGenerate() {
+			rank[name] = i - len(req.GetFileToGenerate())
+		}
+		sort.SliceStable(files, func(i, j int) bool { return rank[files[i].GetName()] < rank[files[j].GetName()] })
+		return files, nil
+	case SortByDependency:
+		return SortFilesByDependency(files)
+	default:
+		sort.Slice(files, func(i, j int) bool { return files[i].GetName() < files[j].GetName() })
+		return files, nil
+	}
 }
 
 func parseFile(ctx context.Context, fd *descriptorpb.FileDescriptorProto,
 	f protoreflect.FileDescriptor) *PKFileDescriptor {
-	comments := ParseComments(fd)
+	locations, comments := parseLocationsAndComments(fd, parseOptionsFromContext(ctx).commentTrimPolicy)
 
 	allFilesMap, _ := AllFilesFromContext(ctx)
 
 	file := &PKFileDescriptor{
 		comments:        comments,
+		locations:       locations,
 		desc:            fd,
 		PackageComments: comments.Get(fmt.Sprintf("%d", packageCommentPath)),
 		SyntaxComments:  comments.Get(fmt.Sprintf("%d", syntaxCommentPath)),
 		FileDescriptor:  f,
 	}
 
+	file.optionTypes = parseOptionsFromContext(ctx).optionTypes()
 	if fd.Options != nil {
 		file.setOptions(fd.Options)
 	}
@@ -140,11 +378,19 @@ func parseFile(ctx context.Context, fd *descriptorpb.FileDescriptorProto,
 	file.Messages = parseMessages(fileCtx, fd.GetMessageType())
 	file.Services = parseServices(fileCtx, fd.GetService())
 	for _, dep := range fd.GetDependency() {
-		file.Dependencies = append(file.Dependencies, allFilesMap[dep])
+		// weak dependencies may be missing from the request
+		if depFile, ok := allFilesMap[dep]; ok {
+			file.Dependencies = append(file.Dependencies, depFile)
+		}
 	}
 	for _, dep := range fd.GetPublicDependency() {
 		file.PublicDependencies = append(file.PublicDependencies, allFilesMap[fd.GetDependency()[dep]])
 	}
+	for _, dep := range fd.GetWeakDependency() {
+		if depFile, ok := allFilesMap[fd.GetDependency()[dep]]; ok {
+			file.WeakDependencies = append(file.WeakDependencies, depFile)
+		}
+	}
 
 	return file
 }
@@ -153,18 +399,19 @@ func parseEnums(ctx context.Context, protos []*descriptorpb.EnumDescriptorProto)
 	enums := make([]*PKEnumDescriptor, len(protos))
 	file, _ := FileDescriptorFromContext(ctx)
 	parent, hasParent := DescriptorFromContext(ctx)
+	names := parseOptionsFromContext(ctx).names()
 
 	for i, ed := range protos {
-		longName := ed.GetName()
-		commentPath := fmt.Sprintf("%d.%d", enumCommentPath, i)
+		longName := names.LongName(file, "", ed.GetName())
+		commentPath := names.CommentPath("", enumCommentPath, i)
 
 		if hasParent {
-			longName = fmt.Sprintf("%s.%s", parent.GetLongName(), longName)
-			commentPath = fmt.Sprintf("%s.%d.%d", parent.path, messageEnumCommentPath, i)
+			longName = names.LongName(file, parent.GetLongName(), ed.GetName())
+			commentPath = names.CommentPath(parent.path, messageEnumCommentPath, i)
 		}
 
 		enums[i] = &PKEnumDescriptor{
-			common:   newCommon(file, commentPath, longName),
+			common:   newCommon(file, commentPath, longName, i),
 			desc:     ed,
 			Comments: file.comments.Get(commentPath),
 			Parent:   parent,
@@ -180,19 +427,23 @@ func parseEnums(ctx context.Context, protos []*descriptorpb.EnumDescriptorProto)
 	return enums
 }
 
+// parseEnumValues parses the values of the enum in the context. Their comment paths are built from the enum's own path,
+// which already includes the paths of any parent messages, so values of nested enums resolve their comments too.
 func parseEnumValues(ctx context.Context, protos []*descriptorpb.EnumValueDescriptorProto) []*PKEnumValueDescriptor {
 	values := make([]*PKEnumValueDescriptor, len(protos))
 	file, _ := FileDescriptorFromContext(ctx)
 	enum, _ := EnumDescriptorFromContext(ctx)
+	names := parseOptionsFromContext(ctx).names()
 
 	for i, vd := range protos {
-		longName := fmt.Sprintf("%s.%s", enum.GetLongName(), vd.GetName())
+		longName := names.LongName(file, enum.GetLongName(), vd.GetName())
+		commentPath := names.CommentPath(enum.path, enumValueCommentPath, i)
 
 		values[i] = &PKEnumValueDescriptor{
-			common:   newCommon(file, "", longName),
+			common:   newCommon(file, commentPath, longName, i),
 			desc:     vd,
 			Enum:     enum,
-			Comments: file.comments.Get(fmt.Sprintf("%s.%d.%d", enum.path, enumValueCommentPath, i)),
+			Comments: file.comments.Get(commentPath),
 		}
 		if vd.Options != nil {
 			values[i].setOptions(vd.Options)
@@ -206,22 +457,18 @@ func parseExtensions(ctx context.Context, protos []*descriptorpb.FieldDescriptor
 	exts := make([]*PKExtensionDescriptor, len(protos))
 	file, _ := FileDescriptorFromContext(ctx)
 	parent, hasParent := DescriptorFromContext(ctx)
+	names := parseOptionsFromContext(ctx).names()
 
 	for i, ext := range protos {
-		commentPath := fmt.Sprintf("%d.%d", extensionCommentPath, i)
-		longName := fmt.Sprintf("%s.%s", ext.GetExtendee(), ext.GetName())
-
-		if strings.Contains(longName, file.GetPackage()) {
-			parts := strings.Split(ext.GetExtendee(), ".")
-			longName = fmt.Sprintf("%s.%s", parts[len(parts)-1], ext.GetName())
-		}
+		commentPath := names.CommentPath("", extensionCommentPath, i)
+		longName := names.ExtensionLongName(file, ext)
 
 		if hasParent {
-			commentPath = fmt.Sprintf("%s.%d.%d", parent.path, messageExtensionCommentPath, i)
+			commentPath = names.CommentPath(parent.path, messageExtensionCommentPath, i)
 		}
 
 		exts[i] = &PKExtensionDescriptor{
-			common:              newCommon(file, commentPath, longName),
+			common:              newCommon(file, commentPath, longName, i),
 			desc:                ext,
 			Comments:            file.comments.Get(commentPath),
 			Parent:              parent,
@@ -235,49 +482,91 @@ func parseExtensions(ctx context.Context, protos []*descriptorpb.FieldDescriptor
 	return exts
 }
 
+// extendeeName returns the extendee relative to the package of the file when the extended message is part of that
+// package (e.g. `Msg` or `Outer.Inner`), or else its fully-qualified name with a leading "." (e.g. `.other.Msg`)
+func extendeeName(file *PKFileDescriptor, extendee string) string {
+	if !strings.HasPrefix(extendee, ".") {
+		extendee = "." + extendee
+	}
+
+	prefix := "."
+	if file.GetPackage() != "" {
+		prefix = fmt.Sprintf(".%s.", file.GetPackage())
+	}
+	if strings.HasPrefix(extendee, prefix) {
+		return strings.TrimPrefix(extendee, prefix)
+	}
+
+	return extendee
+}
+
+// parseAllImports collects the messages, enums and extensions of the file's dependencies, sorted by full name and
+// de-duplicated (see `PKFileDescriptor.GetImports`)
 func parseAllImports(fd *PKFileDescriptor, allFiles map[string]*PKFileDescriptor) {
 	fd.Imports = make([]*PKImportedDescriptor, 0)
+	seen := make(map[string]bool)
+
+	addImport := func(c common) {
+		if !seen[c.GetFullName()] {
+			seen[c.GetFullName()] = true
+			fd.Imports = append(fd.Imports, &PKImportedDescriptor{c})
+		}
+	}
 
 	for _, fileName := range fd.ProtoDesc().GetDependency() {
-		file := allFiles[fileName]
+		file, ok := allFiles[fileName]
+		if !ok {
+			// a weak dependency that wasn't supplied
+			continue
+		}
 
 		for _, d := range file.GetMessages() {
 			// skip map entry objects
-			if !d.ProtoDesc().GetOptions().GetMapEntry() {
-				fd.Imports = append(fd.Imports, &PKImportedDescriptor{d.common})
+			if !d.IsMapEntry() {
+				addImport(d.common)
 			}
 		}
 
 		for _, e := range file.GetEnums() {
-			fd.Imports = append(fd.Imports, &PKImportedDescriptor{e.common})
+			addImport(e.common)
 		}
 
 		for _, ext := range file.GetExtensions() {
-			fd.Imports = append(fd.Imports, &PKImportedDescriptor{ext.common})
+			addImport(ext.common)
 		}
 	}
+
+	sort.SliceStable(fd.Imports, func(i, j int) bool {
+		return fd.Imports[i].GetFullName() < fd.Imports[j].GetFullName()
+	})
 }
 
 func parseMessages(ctx context.Context, protos []*descriptorpb.DescriptorProto) []*PKDescriptor {
 	msgs := make([]*PKDescriptor, len(protos))
 	file, _ := FileDescriptorFromContext(ctx)
 	parent, hasParent := DescriptorFromContext(ctx)
+	names := parseOptionsFromContext(ctx).names()
 
 	for i, md := range protos {
-		longName := md.GetName()
-		commentPath := fmt.Sprintf("%d.%d", messageCommentPath, i)
+		longName := names.LongName(file, "", md.GetName())
+		commentPath := names.CommentPath("", messageCommentPath, i)
 
 		if hasParent {
-			longName = fmt.Sprintf("%s.%s", parent.GetLongName(), longName)
-			commentPath = fmt.Sprintf("%s.%d.%d", parent.path, messageMessageCommentPath, i)
+			longName = names.LongName(file, parent.GetLongName(), md.GetName())
+			commentPath = names.CommentPath(parent.path, messageMessageCommentPath, i)
 		}
 
 		msgs[i] = &PKDescriptor{
-			common:   newCommon(file, commentPath, longName),
+			common:   newCommon(file, commentPath, longName, i),
 			desc:     md,
 			Comments: file.comments.Get(commentPath),
 			Parent:   parent,
 		}
+		if hasParent {
+			msgs[i].MessageDescriptor = parent.GetMessageDescriptor().Messages().ByName(protoreflect.Name(md.GetName()))
+		} else {
+			msgs[i].MessageDescriptor = file.FileDescriptor.Messages().ByName(protoreflect.Name(md.GetName()))
+		}
 		if md.Options != nil {
 			msgs[i].setOptions(md.Options)
 		}
@@ -286,25 +575,59 @@ func parseMessages(ctx context.Context, protos []*descriptorpb.DescriptorProto)
 		msgs[i].Enums = parseEnums(msgCtx, md.GetEnumType())
 		msgs[i].Extensions = parseExtensions(msgCtx, md.GetExtension())
 		msgs[i].Fields = parseMessageFields(msgCtx, md.GetField())
-		msgs[i].Messages = parseMessages(msgCtx, md.GetNestedType())
+		nested := parseMessages(msgCtx, md.GetNestedType())
+		msgs[i].Messages, msgs[i].MapEntries = splitMapEntries(nested)
+		if parseOptionsFromContext(ctx).includeMapEntries {
+			msgs[i].Messages = nested
+		}
+		attachMapEntryComments(msgs[i])
 	}
 
 	return msgs
 }
 
+// splitMapEntries separates the synthetic map entry messages from the regular nested messages
+func splitMapEntries(nested []*PKDescriptor) (msgs []*PKDescriptor, entries []*PKDescriptor) {
+	msgs = make([]*PKDescriptor, 0, len(nested))
+	for _, m := range nested {
+		if m.IsMapEntry() {
+			entries = append(entries, m)
+		} else {
+			msgs = append(msgs, m)
+		}
+	}
+
+	return msgs, entries
+}
+
+// attachMapEntryComments gives the synthetic map entry messages nested in the message the comments of the map fields
+// that declare them, since protoc only records those comments on the field itself
+func attachMapEntryComments(msg *PKDescriptor) {
+	for _, f := range msg.GetMessageFields() {
+		for _, entry := range msg.GetMapEntries() {
+			if entry.GetFullName() == f.GetTypeName() {
+				entry.Comments = f.GetComments()
+			}
+		}
+	}
+}
+
 func parseMessageFields(ctx context.Context, protos []*descriptorpb.FieldDescriptorProto) []*PKFieldDescriptor {
 	fields := make([]*PKFieldDescriptor, len(protos))
 	file, _ := FileDescriptorFromContext(ctx)
 	message, _ := DescriptorFromContext(ctx)
+	names := parseOptionsFromContext(ctx).names()
 
 	for i, fd := range protos {
-		longName := fmt.Sprintf("%s.%s", message.GetLongName(), fd.GetName())
+		longName := names.LongName(file, message.GetLongName(), fd.GetName())
+		commentPath := names.CommentPath(message.path, messageFieldCommentPath, i)
 
 		fields[i] = &PKFieldDescriptor{
-			common:   newCommon(file, "", longName),
-			desc:     fd,
-			Comments: file.comments.Get(fmt.Sprintf("%s.%d.%d", message.path, messageFieldCommentPath, i)),
-			Message:  message,
+			common:          newCommon(file, commentPath, longName, i),
+			desc:            fd,
+			Comments:        file.comments.Get(commentPath),
+			Message:         message,
+			FieldDescriptor: message.GetMessageDescriptor().Fields().ByName(protoreflect.Name(fd.GetName())),
 		}
 		if fd.Options != nil {
 			fields[i].setOptions(fd.Options)
@@ -317,13 +640,14 @@ func parseMessageFields(ctx context.Context, protos []*descriptorpb.FieldDescrip
 func parseServices(ctx context.Context, protos []*descriptorpb.ServiceDescriptorProto) []*PKServiceDescriptor {
 	svcs := make([]*PKServiceDescriptor, len(protos))
 	file, _ := FileDescriptorFromContext(ctx)
+	names := parseOptionsFromContext(ctx).names()
 
 	for i, sd := range protos {
-		longName := sd.GetName()
-		commentPath := fmt.Sprintf("%d.%d", serviceCommentPath, i)
+		longName := names.LongName(file, "", sd.GetName())
+		commentPath := names.CommentPath("", serviceCommentPath, i)
 
 		svcs[i] = &PKServiceDescriptor{
-			common:            newCommon(file, commentPath, longName),
+			common:            newCommon(file, commentPath, longName, i),
 			desc:              sd,
 			Comments:          file.comments.Get(commentPath),
 			ServiceDescriptor: file.FileDescriptor.Services().ByName(protoreflect.Name(sd.GetName())),
@@ -344,18 +668,18 @@ func parseServiceMethods(ctx context.Context, protos []*descriptorpb.MethodDescr
 
 	file, _ := FileDescriptorFromContext(ctx)
 	svc, _ := ServiceDescriptorFromContext(ctx)
+	names := parseOptionsFromContext(ctx).names()
 
 	for i, md := range protos {
-		longName := fmt.Sprintf("%s.%s", svc.GetLongName(), md.GetName())
+		longName := names.LongName(file, svc.GetLongName(), md.GetName())
+		commentPath := names.CommentPath(svc.path, serviceMethodCommentPath, i)
 
 		methods[i] = &PKMethodDescriptor{
-			common:           newCommon(file, "", longName),
+			common:           newCommon(file, commentPath, longName, i),
 			desc:             md,
-			Comments:         file.comments.Get(fmt.Sprintf("%s.%d.%d", svc.path, serviceMethodCommentPath, i)),
+			Comments:         file.comments.Get(commentPath),
 			Service:          svc,
 			MethodDescriptor: svc.ServiceDescriptor.Methods().ByName(protoreflect.Name(md.GetName())),
-			InputType:        file.GetMessage(md.GetInputType()),
-			OutputType:       file.GetMessage(md.GetOutputType()),
 		}
 		if md.Options != nil {
 			methods[i].setOptions(md.Options)
@@ -0,0 +1,473 @@
+package protokit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// cancelingResolver cancels the parse once the descriptors of the named file are being named, and records the files it
+// has seen
+type cancelingResolver struct {
+	defaultNameResolver
+	cancelOn string
+	cancel   context.CancelFunc
+	seen     map[string]bool
+}
+
+func (r *cancelingResolver) LongName(file *PKFileDescriptor, parentLongName, name string) string {
+	r.seen[file.GetName()] = true
+	if file.GetName() == r.cancelOn {
+		r.cancel()
+	}
+
+	return r.defaultNameResolver.LongName(file, parentLongName, name)
+}
+
+func TestParseCodeGenRequestAllFilesContextCanceled(t *testing.T) {
+	req := loadFixture(t, "shop", "shop/shop.proto")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resolver := &cancelingResolver{cancelOn: "common/money.proto", cancel: cancel, seen: make(map[string]bool)}
+
+	files, err := ParseCodeGenRequestAllFilesContext(ctx, req, WithNameResolver(resolver))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseCodeGenRequestAllFilesContext() error = %v, want %v", err, context.Canceled)
+	}
+	if files != nil {
+		t.Errorf("ParseCodeGenRequestAllFilesContext() = %d files, want none", len(files))
+	}
+	if !resolver.seen["common/money.proto"] || resolver.seen["shop/shop.proto"] {
+		t.Errorf("parsed files = %v, want the parse to stop after common/money.proto", resolver.seen)
+	}
+}
+
+func TestParseCodeGenRequestAllFilesContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	_, err := ParseCodeGenRequestAllFilesContext(ctx, loadFixture(t, "shop", "shop/shop.proto"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ParseCodeGenRequestAllFilesContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestImportsStable(t *testing.T) {
+	importNames := func() []string {
+		f := parseFixtureFile(t, "shop", "shop/shop.proto")
+		names := make([]string, 0, len(f.GetImports()))
+		for _, imp := range f.GetImports() {
+			names = append(names, imp.GetFullName())
+		}
+		return names
+	}
+
+	want := importNames()
+	if len(want) == 0 {
+		t.Fatal("GetImports() is empty")
+	}
+	for i := 0; i < 10; i++ {
+		if got := importNames(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("GetImports() = %q on run %d, want %q", got, i, want)
+		}
+	}
+}
+
+func TestParseAllImports(t *testing.T) {
+	files := parseFixture(t, "shop")
+
+	tests := []struct {
+		name string
+		deps []string
+		want []string
+	}{
+		{
+			name: "sorted",
+			deps: []string{"google/protobuf/timestamp.proto", "extra/weak.proto", "common/reexport.proto"},
+			want: []string{".common.Wrapper", ".extra.Extra", ".google.protobuf.Timestamp"},
+		},
+		{
+			name: "deduplicated",
+			deps: []string{"common/reexport.proto", "extra/weak.proto", "common/reexport.proto"},
+			want: []string{".common.Wrapper", ".extra.Extra"},
+		},
+		{
+			name: "missing dependency",
+			deps: []string{"extra/absent.proto", "extra/weak.proto"},
+			want: []string{".extra.Extra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &PKFileDescriptor{desc: &descriptorpb.FileDescriptorProto{Name: proto.String("test.proto"), Dependency: tt.deps}}
+			parseAllImports(f, files)
+
+			got := make([]string, 0, len(f.GetImports()))
+			for _, imp := range f.GetImports() {
+				got = append(got, imp.GetFullName())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetImports() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisableExtensionRegistration(t *testing.T) {
+	req := loadFixture(t, "host", "host/service.proto")
+
+	// register the extension the way a host linking its Go types would, before the request is parsed
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: req.GetProtoFile()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := files.FindDescriptorByName("host.flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostType, err := protoregistry.GlobalTypes.FindExtensionByName("host.flag")
+	if err != nil {
+		hostType = dynamicpb.NewExtensionType(desc.(protoreflect.ExtensionDescriptor))
+		if err := protoregistry.GlobalTypes.RegisterExtension(hostType); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parsed, err := ParseCodeGenRequestAllFiles(req, DisableExtensionRegistration())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range parsed {
+		if !f.IsFileToGenerate {
+			continue
+		}
+		if got, ok := f.GetFileOptionBool("host.flag"); !ok || !got {
+			t.Errorf(`GetFileOptionBool("host.flag") = %v, %v, want true, true`, got, ok)
+		}
+	}
+	if got, _ := protoregistry.GlobalTypes.FindExtensionByName("host.flag"); got != hostType {
+		t.Error("the extension registered by the host was replaced")
+	}
+}
+
+func TestParseTwice(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		f := parseFixtureFile(t, "options", "user/user.proto")
+		if got, ok := f.GetFileOptionString("opts.policy"); !ok || got != "strict" {
+			t.Errorf(`parse %d: GetFileOptionString("opts.policy") = %q, %v, want "strict", true`, i, got, ok)
+		}
+	}
+}
+
+func TestExtensionNumberConflict(t *testing.T) {
+	// make sure opts.policy is registered
+	parseFixture(t, "options")
+
+	conflict := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("conflict/conflict.proto"),
+		Package:    proto.String("conflict"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("other_policy"),
+			Number:   proto.Int32(50001),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Extendee: proto.String(".google.protobuf.FileOptions"),
+			JsonName: proto.String("otherPolicy"),
+		}},
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"conflict/conflict.proto"},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			conflict,
+		},
+	}
+
+	_, err := ParseCodeGenRequestAllFiles(req)
+	if err == nil || !strings.Contains(err.Error(), "opts.policy") {
+		t.Fatalf("ParseCodeGenRequestAllFiles() error = %v, want a conflict with opts.policy", err)
+	}
+
+	xt, err := protoregistry.GlobalTypes.FindExtensionByNumber("google.protobuf.FileOptions", 50001)
+	if err != nil || xt.TypeDescriptor().FullName() != "opts.policy" {
+		t.Errorf("extension 50001 of FileOptions = %v, %v, want opts.policy", xt, err)
+	}
+}
+
+func TestIncludeMapEntries(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto", IncludeMapEntries())
+	item := f.GetMessage("Item")
+
+	counts := item.GetMessage("CountsEntry")
+	if counts == nil || !counts.IsMapEntry() {
+		t.Fatalf(`GetMessage("CountsEntry") = %v, want the map entry`, counts)
+	}
+	if got := item.GetMapEntries()[0]; got != counts {
+		t.Errorf("GetMapEntries()[0] = %p, want the nested message %p", got, counts)
+	}
+	if got := item.GetMessageField("counts").GetMessageType(); got != counts {
+		t.Errorf("counts.GetMessageType() = %p, want the nested message %p", got, counts)
+	}
+
+	var visited []string
+	Walk(f, func(d Descriptor) bool {
+		if m, ok := d.(*PKDescriptor); ok && strings.HasPrefix(m.GetFullName(), ".shop.Item") {
+			visited = append(visited, m.GetName())
+		}
+		return true
+	})
+	if want := []string{"Item", "CountsEntry", "Detail", "Note", "PricesEntry"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("Walk visited %q, want %q", visited, want)
+	}
+
+	// the clone keeps sharing the entries between the nested messages and the map entries
+	clone := f.Clone().GetMessage("Item")
+	if clone.GetMessage("CountsEntry") != clone.GetMapEntries()[0] {
+		t.Error("the cloned CountsEntry isn't shared between GetMessages and GetMapEntries")
+	}
+	if got := clone.GetMessage("CountsEntry").GetParent(); got != clone {
+		t.Errorf("cloned CountsEntry.GetParent() = %p, want the cloned Item %p", got, clone)
+	}
+	if clone.GetMessage("CountsEntry") == counts {
+		t.Error("the clone shares CountsEntry with the original")
+	}
+}
+
+func TestNormalizeFileNames(t *testing.T) {
+	req := loadFixture(t, "shop", `shop\shop.proto`)
+	for _, pf := range req.GetProtoFile() {
+		pf.Name = proto.String(strings.ReplaceAll(pf.GetName(), "/", `\`))
+		for i, dep := range pf.GetDependency() {
+			pf.Dependency[i] = strings.ReplaceAll(dep, "/", `\`)
+		}
+	}
+
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var generate []string
+	for _, f := range files {
+		if strings.Contains(f.GetName(), `\`) {
+			t.Errorf("file name %q wasn't normalized", f.GetName())
+		}
+		if f.IsFileToGenerate {
+			generate = append(generate, f.GetName())
+		}
+	}
+	if want := []string{"shop/shop.proto"}; !reflect.DeepEqual(generate, want) {
+		t.Fatalf("files to generate = %q, want %q", generate, want)
+	}
+
+	var shop *PKFileDescriptor
+	for _, f := range files {
+		if f.GetName() == "shop/shop.proto" {
+			shop = f
+		}
+	}
+	var deps []string
+	for _, dep := range shop.GetDependencies() {
+		deps = append(deps, dep.GetName())
+	}
+	want := []string{"common/reexport.proto", "google/protobuf/timestamp.proto", "extra/weak.proto"}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("GetDependencies() = %q, want %q", deps, want)
+	}
+	if got := shop.GetMessage("Item").GetMessageField("price").GetMessageType(); got == nil {
+		t.Error("price.GetMessageType() = nil, want common.Money")
+	}
+}
+
+func TestMissingFileToGenerate(t *testing.T) {
+	req := loadFixture(t, "shop", "shop/shop.proto", "shop/missing.proto")
+
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err == nil {
+		t.Fatalf("ParseCodeGenRequestAllFiles() = %d files, want an error", len(files))
+	}
+	if !strings.Contains(err.Error(), `"shop/missing.proto"`) {
+		t.Errorf("ParseCodeGenRequestAllFiles() error = %q, want it to name shop/missing.proto", err)
+	}
+}
+
+func TestExtensionLongName(t *testing.T) {
+	tests := []struct {
+		name               string
+		pkg, extendee      string
+		longName, fullName string
+	}{
+		{name: "same package", pkg: "foo", extendee: ".foo.Msg", longName: "Msg.ext", fullName: ".foo.Msg.ext"},
+		{name: "nested extendee", pkg: "foo", extendee: ".foo.Outer.Inner", longName: "Outer.Inner.ext",
+			fullName: ".foo.Outer.Inner.ext"},
+		{name: "subpackage", pkg: "foo", extendee: ".foo.bar.Msg", longName: "bar.Msg.ext",
+			fullName: ".foo.bar.Msg.ext"},
+		{name: "other package", pkg: "foo", extendee: ".google.protobuf.FieldOptions",
+			longName: ".google.protobuf.FieldOptions.ext", fullName: ".google.protobuf.FieldOptions.ext"},
+		{name: "sibling prefix", pkg: "foo", extendee: ".foobar.Msg", longName: ".foobar.Msg.ext",
+			fullName: ".foobar.Msg.ext"},
+		{name: "package suffix", pkg: "foo", extendee: ".bar.foo.Msg", longName: ".bar.foo.Msg.ext",
+			fullName: ".bar.foo.Msg.ext"},
+		{name: "empty package extendee", pkg: "foo", extendee: ".Msg", longName: ".Msg.ext", fullName: ".Msg.ext"},
+		{name: "no package", extendee: ".Msg", longName: "Msg.ext", fullName: ".Msg.ext"},
+		{name: "no package, qualified extendee", extendee: ".foo.Msg", longName: "foo.Msg.ext", fullName: ".foo.Msg.ext"},
+		{name: "unqualified extendee", pkg: "foo", extendee: "foo.Msg", longName: "Msg.ext", fullName: ".foo.Msg.ext"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &PKFileDescriptor{desc: &descriptorpb.FileDescriptorProto{Package: proto.String(tt.pkg)}}
+			ext := &descriptorpb.FieldDescriptorProto{Name: proto.String("ext"), Extendee: proto.String(tt.extendee)}
+
+			longName := defaultNameResolver{}.ExtensionLongName(f, ext)
+			if longName != tt.longName {
+				t.Errorf("ExtensionLongName() = %q, want %q", longName, tt.longName)
+			}
+			if got := newCommon(f, "", longName, 0).FullName; got != tt.fullName {
+				t.Errorf("GetFullName() = %q, want %q", got, tt.fullName)
+			}
+		})
+	}
+
+	// as parsed
+	legacy := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+	if got := legacy.GetMessage("Order").GetExtensions()[0].GetLongName(); got != "Order.note" {
+		t.Errorf("note.GetLongName() = %q, want Order.note", got)
+	}
+	opts := parseFixtureFile(t, "options", "opts/options.proto")
+	if got := opts.GetExtensions()[0].GetLongName(); got != ".google.protobuf.FileOptions.policy" {
+		t.Errorf("policy.GetLongName() = %q, want .google.protobuf.FileOptions.policy", got)
+	}
+}
+
+func TestParseIsolated(t *testing.T) {
+	req := loadFixture(t, "dynamic", "dyn/user.proto")
+
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(req *pluginpb.CodeGeneratorRequest) {
+			defer wg.Done()
+
+			files, types, err := ParseIsolated(req)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if _, err := types.FindExtensionByName("dyn.owner"); err != nil {
+				errs <- fmt.Errorf("local registry: %v", err)
+			}
+			for _, f := range files {
+				if f.GetName() != "dyn/user.proto" {
+					continue
+				}
+				if got, ok := f.GetFileOptionString("dyn.owner"); !ok || got != "team-b" {
+					errs <- fmt.Errorf(`GetFileOptionString("dyn.owner") = %q, %v, want "team-b", true`, got, ok)
+				}
+			}
+		}(proto.Clone(req).(*pluginpb.CodeGeneratorRequest))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if _, err := protoregistry.GlobalTypes.FindExtensionByName("dyn.owner"); err == nil {
+		t.Error("ParseIsolated registered dyn.owner globally")
+	}
+}
+
+func TestWithOutputSort(t *testing.T) {
+	req := loadFixture(t, "shop", "shop/shop.proto", "common/money.proto")
+	protoFiles := make([]string, 0, len(req.GetProtoFile()))
+	for _, fd := range req.GetProtoFile() {
+		protoFiles = append(protoFiles, fd.GetName())
+	}
+
+	tests := []struct {
+		sort OutputSort
+		want []string
+	}{
+		{
+			sort: SortAlphabetical,
+			want: []string{
+				"common/money.proto", "common/reexport.proto", "extra/weak.proto", "google/protobuf/timestamp.proto",
+				"shop/shop.proto",
+			},
+		},
+		{
+			// the files to generate first, then the others in the order of the request
+			sort: SortAsRequested,
+			want: []string{
+				"shop/shop.proto", "common/money.proto", "google/protobuf/timestamp.proto", "common/reexport.proto",
+				"extra/weak.proto",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		files, err := ParseCodeGenRequestAllFiles(proto.Clone(req).(*pluginpb.CodeGeneratorRequest), WithOutputSort(tt.sort))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]string, 0, len(files))
+		for _, f := range files {
+			got = append(got, f.GetName())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WithOutputSort(%d) = %q, want %q (request order %q)", tt.sort, got, tt.want, protoFiles)
+		}
+	}
+
+	// every file comes after the files it imports
+	files, err := ParseCodeGenRequestAllFiles(proto.Clone(req).(*pluginpb.CodeGeneratorRequest), WithOutputSort(SortByDependency))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(protoFiles) {
+		t.Fatalf("WithOutputSort(SortByDependency) = %d files, want %d", len(files), len(protoFiles))
+	}
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for _, dep := range f.GetDependencies() {
+			if !seen[dep.GetName()] {
+				t.Errorf("WithOutputSort(SortByDependency): %s comes before its import %s", f.GetName(), dep.GetName())
+			}
+		}
+		seen[f.GetName()] = true
+	}
+	alphabetical, err := ParseCodeGenRequestAllFiles(proto.Clone(req).(*pluginpb.CodeGeneratorRequest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := SortFilesByDependency(alphabetical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if files[i].GetName() != want[i].GetName() {
+			t.Errorf("WithOutputSort(SortByDependency)[%d] = %s, want %s", i, files[i].GetName(), want[i].GetName())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package protokit
 
 import (
 	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/pluginpb"
 	"io"
@@ -9,6 +10,9 @@ import (
 	"os"
 )
 
+// the tag number of `file` in CodeGeneratorResponse
+const fileFieldNumber = 15
+
 // Plugin describes an interface for running protoc code generator plugins
 type Plugin interface {
 	Generate(req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error)
@@ -34,6 +38,107 @@ func RunPluginWithIO(p Plugin, r io.Reader, w io.Writer) error {
 	return writeResponse(w, resp)
 }
 
+// StreamingPlugin describes an interface for protoc code generator plugins that add their output files one at a time to a
+// `ResponseBuilder` rather than building the whole response in memory
+type StreamingPlugin interface {
+	GenerateTo(req *pluginpb.CodeGeneratorRequest, resp *ResponseBuilder) error
+}
+
+// RunStreamingPlugin runs the supplied protokit by reading input from stdin and generating output to stdout.
+func RunStreamingPlugin(p StreamingPlugin) error {
+	return RunStreamingPluginWithIO(p, os.Stdin, os.Stdout)
+}
+
+// RunStreamingPluginWithIO runs the supplied protokit using the supplied reader and writer for IO.
+func RunStreamingPluginWithIO(p StreamingPlugin, r io.Reader, w io.Writer) error {
+	req, err := readRequest(r)
+	if err != nil {
+		return err
+	}
+
+	resp := NewResponseBuilder()
+	if err := p.GenerateTo(req, resp); err != nil {
+		return err
+	}
+
+	_, err = resp.WriteTo(w)
+	return err
+}
+
+// A ResponseBuilder assembles a `CodeGeneratorResponse` incrementally. Each file is encoded as soon as it's added, so the
+// generated content doesn't need to be retained by the plugin, and only the encoded bytes are kept until the response is
+// written.
+type ResponseBuilder struct {
+	resp  *pluginpb.CodeGeneratorResponse
+	files []byte
+}
+
+// NewResponseBuilder returns a new, empty ResponseBuilder
+func NewResponseBuilder() *ResponseBuilder {
+	return &ResponseBuilder{resp: new(pluginpb.CodeGeneratorResponse)}
+}
+
+// AddFile encodes the file into the response
+func (b *ResponseBuilder) AddFile(file *pluginpb.CodeGeneratorResponse_File) error {
+	data, err := proto.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	b.files = protowire.AppendTag(b.files, fileFieldNumber, protowire.BytesType)
+	b.files = protowire.AppendBytes(b.files, data)
+	return nil
+}
+
+// SetError sets the error message of the response
+func (b *ResponseBuilder) SetError(msg string) { b.resp.Error = proto.String(msg) }
+
+// SetSupportedFeatures sets the features supported by the plugin (see `CodeGeneratorResponse_Feature`)
+func (b *ResponseBuilder) SetSupportedFeatures(features uint64) {
+	b.resp.SupportedFeatures = proto.Uint64(features)
+}
+
+// Bytes returns the encoded response
+func (b *ResponseBuilder) Bytes() ([]byte, error) {
+	data, err := proto.Marshal(b.resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, b.files...), nil
+}
+
+// Response decodes the assembled response
+func (b *ResponseBuilder) Response() (*pluginpb.CodeGeneratorResponse, error) {
+	data, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(pluginpb.CodeGeneratorResponse)
+	if err := proto.Unmarshal(data, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// WriteTo writes the encoded response to w
+func (b *ResponseBuilder) WriteTo(w io.Writer) (int64, error) {
+	data, err := proto.Marshal(b.resp)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	if err != nil {
+		return int64(n), err
+	}
+
+	m, err := w.Write(b.files)
+	return int64(n + m), err
+}
+
 func readRequest(r io.Reader) (*pluginpb.CodeGeneratorRequest, error) {
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
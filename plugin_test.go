@@ -0,0 +1,120 @@
+package protokit
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// fileListPlugin generates one file per file to generate, listing its messages
+type fileListPlugin struct{}
+
+func (fileListPlugin) GenerateTo(req *pluginpb.CodeGeneratorRequest, resp *ResponseBuilder) error {
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err != nil {
+		return err
+	}
+
+	resp.SetSupportedFeatures(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL))
+	for _, f := range files {
+		if !f.IsFileToGenerate {
+			continue
+		}
+
+		content := new(bytes.Buffer)
+		for _, m := range f.GetMessages() {
+			fmt.Fprintln(content, m.GetFullName())
+		}
+		err := resp.AddFile(&pluginpb.CodeGeneratorResponse_File{
+			Name:    proto.String(f.GetName() + ".txt"),
+			Content: proto.String(content.String()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestRunStreamingPluginWithIO(t *testing.T) {
+	req := loadFixture(t, "shop", "common/money.proto", "shop/shop.proto")
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(bytes.Buffer)
+	if err := RunStreamingPluginWithIO(fileListPlugin{}, bytes.NewReader(data), out); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := new(pluginpb.CodeGeneratorResponse)
+	if err := proto.Unmarshal(out.Bytes(), resp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &pluginpb.CodeGeneratorResponse{
+		SupportedFeatures: proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)),
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{Name: proto.String("common/money.proto.txt"), Content: proto.String(".common.Money\n")},
+			{
+				Name:    proto.String("shop/shop.proto.txt"),
+				Content: proto.String(".shop.Item\n.shop.GetItemRequest\n.shop.ListItemsResponse\n"),
+			},
+		},
+	}
+	if !proto.Equal(resp, want) {
+		t.Errorf("response = %v, want %v", resp, want)
+	}
+}
+
+func TestResponseBuilder(t *testing.T) {
+	b := NewResponseBuilder()
+	for i := 0; i < 3; i++ {
+		err := b.AddFile(&pluginpb.CodeGeneratorResponse_File{
+			Name:    proto.String(fmt.Sprintf("file%d.txt", i)),
+			Content: proto.String(fmt.Sprintf("content %d", i)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.SetError("partial failure")
+
+	resp, err := b.Response()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetError(); got != "partial failure" {
+		t.Errorf("GetError() = %q, want %q", got, "partial failure")
+	}
+	if got := len(resp.GetFile()); got != 3 {
+		t.Fatalf("response has %d files, want 3", got)
+	}
+	for i, f := range resp.GetFile() {
+		if want := fmt.Sprintf("file%d.txt", i); f.GetName() != want {
+			t.Errorf("file %d name = %q, want %q", i, f.GetName(), want)
+		}
+		if want := fmt.Sprintf("content %d", i); f.GetContent() != want {
+			t.Errorf("file %d content = %q, want %q", i, f.GetContent(), want)
+		}
+	}
+
+	// WriteTo writes the same bytes
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(bytes.Buffer)
+	n, err := b.WriteTo(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) || !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("WriteTo() wrote %d bytes, want the %d bytes of Bytes()", n, len(data))
+	}
+}
@@ -0,0 +1,600 @@
+package protokit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// maxFieldNumber is the largest field number, written as `max` in reserved and extension ranges
+	maxFieldNumber = 536870911
+	// maxEnumNumber is the largest enum value number, written as `max` in enum reserved ranges
+	maxEnumNumber = math.MaxInt32
+)
+
+// GenerateProtoSource reconstructs the .proto source of the file: the syntax (or edition) line, the package, the imports
+// and options, then the enums, messages, extensions and services in declaration order. Fields are rendered with their
+// labels, types, numbers and options (standard and custom ones), and the leading comments of the descriptors are kept.
+// Type references are fully-qualified, so the output compiles with protoc given the same imports.
+//
+// Unlike `DumpText`, which is meant for debugging, the output is valid proto syntax. An error is returned for files with
+// an unknown syntax, for group extensions and for top-level map entry messages, which can't be reconstructed from the
+// parsed descriptors.
+func GenerateProtoSource(file *PKFileDescriptor) (string, error) {
+	w := &sourceWriter{textDumper: textDumper{b: new(strings.Builder)}, file: file}
+
+	switch file.GetSyntaxEnum() {
+	case SyntaxProto2, SyntaxProto3:
+		w.comments(file.GetSyntaxComments())
+		w.line("syntax = %q;", file.GetSyntaxEnum().String())
+	case SyntaxEditions:
+		w.comments(file.GetSyntaxComments())
+		w.line("edition = %q;", strings.TrimPrefix(file.GetEdition().String(), "EDITION_"))
+	default:
+		return "", fmt.Errorf("file %q has an unknown syntax %q", file.GetName(), file.GetSyntax())
+	}
+
+	if file.GetPackage() != "" {
+		w.blank()
+		w.comments(file.GetPackageComments())
+		w.line("package %s;", file.GetPackage())
+	}
+
+	if deps := file.ProtoDesc().GetDependency(); len(deps) > 0 {
+		w.blank()
+		kinds := make(map[int32]string)
+		for _, idx := range file.ProtoDesc().GetPublicDependency() {
+			kinds[idx] = "public "
+		}
+		for _, idx := range file.ProtoDesc().GetWeakDependency() {
+			kinds[idx] = "weak "
+		}
+		for idx, dep := range deps {
+			w.line("import %s%q;", kinds[int32(idx)], dep)
+		}
+	}
+
+	if w.hasOptions(file.ProtoDesc().GetOptions()) {
+		w.blank()
+		w.options(file.ProtoDesc().GetOptions())
+	}
+
+	for _, e := range file.GetEnums() {
+		w.blank()
+		w.enum(e)
+	}
+	for _, msg := range file.GetMessages() {
+		if msg.IsMapEntry() {
+			return "", fmt.Errorf("message %q is a top-level map entry, which isn't supported", msg.GetFullName())
+		}
+
+		w.blank()
+		if err := w.message(msg); err != nil {
+			return "", err
+		}
+	}
+	if len(file.GetExtensions()) > 0 {
+		w.blank()
+		if err := w.extensions(file.GetExtensions()); err != nil {
+			return "", err
+		}
+	}
+	for _, svc := range file.GetServices() {
+		w.blank()
+		w.service(svc)
+	}
+
+	return w.b.String(), nil
+}
+
+type sourceWriter struct {
+	textDumper
+	file *PKFileDescriptor
+}
+
+func (w *sourceWriter) blank() {
+	w.b.WriteString("\n")
+}
+
+// comments writes the leading comments as `//` lines
+func (w *sourceWriter) comments(c *Comment) {
+	if c.GetLeading() == "" {
+		return
+	}
+
+	for _, l := range strings.Split(c.GetLeading(), "\n") {
+		if l = strings.TrimRight(l, " \t"); l == "" {
+			w.line("//")
+			continue
+		}
+		w.line("// %s", l)
+	}
+}
+
+func (w *sourceWriter) enum(e *PKEnumDescriptor) {
+	w.comments(e.GetComments())
+	w.line("enum %s {", e.GetName())
+	w.indent++
+	w.options(e.ProtoDesc().GetOptions())
+
+	ranges := make([]string, 0, len(e.ProtoDesc().GetReservedRange()))
+	for _, r := range e.ProtoDesc().GetReservedRange() {
+		// enum reserved ranges are inclusive
+		ranges = append(ranges, formatRange(r.GetStart(), r.GetEnd(), maxEnumNumber))
+	}
+	w.reserved(ranges, e.ProtoDesc().GetReservedName())
+
+	for _, v := range e.GetValues() {
+		w.comments(v.GetComments())
+		w.line("%s = %d%s;", v.GetName(), v.GetNumber(), w.compactOptions(v.ProtoDesc().GetOptions()))
+	}
+	w.indent--
+	w.line("}")
+}
+
+func (w *sourceWriter) message(msg *PKDescriptor) error {
+	w.comments(msg.GetComments())
+	w.line("message %s {", msg.GetName())
+	w.indent++
+	if err := w.messageBody(msg); err != nil {
+		return err
+	}
+	w.indent--
+	w.line("}")
+
+	return nil
+}
+
+// messageBody writes the declarations of the message, which are shared by messages and groups
+func (w *sourceWriter) messageBody(msg *PKDescriptor) error {
+	desc := msg.ProtoDesc()
+	w.options(desc.GetOptions())
+
+	ranges := make([]string, 0, len(desc.GetReservedRange()))
+	for _, r := range desc.GetReservedRange() {
+		// message reserved ranges are exclusive
+		ranges = append(ranges, formatRange(r.GetStart(), r.GetEnd()-1, maxFieldNumber))
+	}
+	w.reserved(ranges, desc.GetReservedName())
+
+	for _, r := range desc.GetExtensionRange() {
+		w.line("extensions %s%s;", formatRange(r.GetStart(), r.GetEnd()-1, maxFieldNumber),
+			w.compactOptions(r.GetOptions()))
+	}
+
+	for _, e := range msg.GetEnums() {
+		w.enum(e)
+	}
+
+	groups := make(map[*PKDescriptor]bool)
+	for _, f := range msg.GetMessageFields() {
+		if w.isGroup(f) {
+			groups[f.GetMessageType()] = true
+		}
+	}
+	for _, nested := range msg.GetMessages() {
+		// groups are written with their fields, and map entries (part of the nested messages with `IncludeMapEntries`)
+		// with the map syntax
+		if groups[nested] || nested.IsMapEntry() {
+			continue
+		}
+		if err := w.message(nested); err != nil {
+			return err
+		}
+	}
+
+	if err := w.extensions(msg.GetExtensions()); err != nil {
+		return err
+	}
+
+	oneofs := desc.GetOneofDecl()
+	written := make(map[int32]bool)
+	for _, f := range msg.GetMessageFields() {
+		if !f.InRealOneof() {
+			if err := w.field(f, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		idx := f.ProtoDesc().GetOneofIndex()
+		if written[idx] || int(idx) >= len(oneofs) {
+			continue
+		}
+		written[idx] = true
+
+		w.comments(w.file.GetCommentForPath(fmt.Sprintf("%s.%d.%d", msg.GetPath(), messageOneofCommentPath, idx)))
+		w.line("oneof %s {", oneofs[idx].GetName())
+		w.indent++
+		w.options(oneofs[idx].GetOptions())
+		for _, member := range msg.GetMessageFields() {
+			if member.ProtoDesc().OneofIndex == nil || member.ProtoDesc().GetOneofIndex() != idx {
+				continue
+			}
+			if err := w.field(member, true); err != nil {
+				return err
+			}
+		}
+		w.indent--
+		w.line("}")
+	}
+
+	return nil
+}
+
+// isGroup returns whether the field is declared with the proto2 `group` syntax. Under editions, groups are regular
+// message fields with the delimited encoding feature.
+func (w *sourceWriter) isGroup(f *PKFieldDescriptor) bool {
+	return f.IsGroup() && !w.file.IsEditions() && f.GetMessageType() != nil
+}
+
+func (w *sourceWriter) field(f *PKFieldDescriptor, inOneof bool) error {
+	desc := f.ProtoDesc()
+	w.comments(f.GetComments())
+
+	options := w.fieldOptions(desc, JSONCamelCase(f.GetName()))
+	switch {
+	case f.IsMap():
+		w.line("map<%s, %s> %s = %d%s;", fieldTypeName(f.GetMapKey().ProtoDesc()),
+			fieldTypeName(f.GetMapValue().ProtoDesc()), f.GetName(), desc.GetNumber(), options)
+	case w.isGroup(f):
+		w.line("%sgroup %s = %d%s {", w.label(desc, inOneof), f.GetMessageType().GetName(), desc.GetNumber(), options)
+		w.indent++
+		if err := w.messageBody(f.GetMessageType()); err != nil {
+			return err
+		}
+		w.indent--
+		w.line("}")
+	default:
+		w.line("%s%s %s = %d%s;", w.label(desc, inOneof), fieldTypeName(desc), f.GetName(), desc.GetNumber(), options)
+	}
+
+	return nil
+}
+
+// extensions writes the extensions in `extend` blocks, one per extendee in the order the extendees first appear
+func (w *sourceWriter) extensions(exts []*PKExtensionDescriptor) error {
+	byExtendee := make(map[string][]*PKExtensionDescriptor)
+	extendees := make([]string, 0)
+	for _, ext := range exts {
+		if _, ok := byExtendee[ext.GetExtendee()]; !ok {
+			extendees = append(extendees, ext.GetExtendee())
+		}
+		byExtendee[ext.GetExtendee()] = append(byExtendee[ext.GetExtendee()], ext)
+	}
+
+	for _, extendee := range extendees {
+		w.line("extend %s {", extendee)
+		w.indent++
+		for _, ext := range byExtendee[extendee] {
+			desc := ext.ProtoDesc()
+			if desc.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP && !w.file.IsEditions() {
+				return fmt.Errorf("extension %q is a group, which isn't supported", ext.GetFullName())
+			}
+
+			w.comments(ext.GetComments())
+			w.line("%s%s %s = %d%s;", w.label(desc, false), fieldTypeName(desc), ext.GetName(), desc.GetNumber(),
+				w.fieldOptions(desc, JSONCamelCase(ext.GetName())))
+		}
+		w.indent--
+		w.line("}")
+	}
+
+	return nil
+}
+
+func (w *sourceWriter) service(svc *PKServiceDescriptor) {
+	w.comments(svc.GetComments())
+	w.line("service %s {", svc.GetName())
+	w.indent++
+	w.options(svc.ProtoDesc().GetOptions())
+	for _, m := range svc.GetMethods() {
+		w.comments(m.GetComments())
+		signature := fmt.Sprintf("rpc %s(%s) returns (%s)", m.GetName(),
+			streamType(m.IsClientStreaming(), m.GetInputTypeName()),
+			streamType(m.IsServerStreaming(), m.GetOutputTypeName()))
+		if !w.hasOptions(m.ProtoDesc().GetOptions()) {
+			w.line("%s;", signature)
+			continue
+		}
+
+		w.line("%s {", signature)
+		w.indent++
+		w.options(m.ProtoDesc().GetOptions())
+		w.indent--
+		w.line("}")
+	}
+	w.indent--
+	w.line("}")
+}
+
+// label returns the label keyword of the field followed by a space, or an empty string when the field is declared
+// without one (oneof members, proto3 singular fields and non-repeated fields under editions)
+func (w *sourceWriter) label(desc *descriptorpb.FieldDescriptorProto, inOneof bool) string {
+	switch {
+	case inOneof:
+		return ""
+	case desc.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+		return "repeated "
+	case w.file.IsEditions():
+		return ""
+	case w.file.IsProto3():
+		if desc.GetProto3Optional() {
+			return "optional "
+		}
+		return ""
+	default:
+		return labelName(desc) + " "
+	}
+}
+
+func (w *sourceWriter) reserved(ranges, names []string) {
+	if len(ranges) > 0 {
+		w.line("reserved %s;", strings.Join(ranges, ", "))
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		// reserved names are identifiers under editions, and string literals before
+		if w.file.IsEditions() {
+			quoted[i] = name
+		} else {
+			quoted[i] = strconv.Quote(name)
+		}
+	}
+	w.line("reserved %s;", strings.Join(quoted, ", "))
+}
+
+// formatRange formats an inclusive range of numbers (e.g. `5`, `9 to 11` or `100 to max`)
+func formatRange(start, end, max int32) string {
+	switch end {
+	case start:
+		return strconv.Itoa(int(start))
+	case max:
+		return fmt.Sprintf("%d to max", start)
+	default:
+		return fmt.Sprintf("%d to %d", start, end)
+	}
+}
+
+// fieldOptions returns the bracketed options of a field or extension, including the `default` and `json_name`
+// pseudo-options (the latter only when it differs from the default JSON name)
+func (w *sourceWriter) fieldOptions(desc *descriptorpb.FieldDescriptorProto, defaultJSONName string) string {
+	parts := make([]string, 0)
+	if desc.DefaultValue != nil {
+		def := desc.GetDefaultValue()
+		switch desc.GetType() {
+		case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+			def = quoteProto([]byte(def))
+		case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+			// bytes defaults are stored C-escaped
+			def = `"` + def + `"`
+		}
+		parts = append(parts, "default = "+def)
+	}
+	if desc.JsonName != nil && desc.GetJsonName() != defaultJSONName {
+		parts = append(parts, "json_name = "+strconv.Quote(desc.GetJsonName()))
+	}
+
+	return bracketOptions(append(parts, w.optionAssignments(desc.GetOptions())...))
+}
+
+// compactOptions returns the options in brackets (e.g. ` [deprecated = true]`), or an empty string when none are set
+func (w *sourceWriter) compactOptions(options proto.Message) string {
+	return bracketOptions(w.optionAssignments(options))
+}
+
+func bracketOptions(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// options writes the options as `option` statements
+func (w *sourceWriter) options(options proto.Message) {
+	for _, a := range w.optionAssignments(options) {
+		w.line("option %s;", a)
+	}
+}
+
+func (w *sourceWriter) hasOptions(options proto.Message) bool {
+	return len(w.optionAssignments(options)) > 0
+}
+
+// optionAssignments returns the `name = value` assignments of the options that are set, standard options first and then
+// custom ones, each ordered by field number. Custom options are resolved from the extensions defined in the request.
+func (w *sourceWriter) optionAssignments(options proto.Message) []string {
+	msg := w.file.dynamicOptions(options)
+	if msg == nil {
+		return nil
+	}
+
+	assignments := make([]string, 0)
+	for _, fd := range setFields(msg) {
+		// uninterpreted options have been resolved by protoc and map entries are written with the map syntax
+		if !fd.IsExtension() && (fd.Name() == "uninterpreted_option" || fd.Name() == "map_entry") {
+			continue
+		}
+		assignments = append(assignments, assignOption(optionName(fd), fd, msg.Get(fd))...)
+	}
+
+	return assignments
+}
+
+// assignOption returns the assignments of an option value. Repeated options are assigned element by element, and
+// singular messages are flattened into assignments of their fields (e.g. `features.field_presence = IMPLICIT`) unless
+// they contain repeated fields, in which case they're assigned as an aggregate value.
+func assignOption(name string, fd protoreflect.FieldDescriptor, v protoreflect.Value) []string {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		assignments := make([]string, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			assignments[i] = name + " = " + formatOptionValue(fd, list.Get(i))
+		}
+		return assignments
+	case fd.Message() != nil && !fd.IsMap() && isFlat(v.Message()):
+		assignments := make([]string, 0)
+		for _, sub := range setFields(v.Message()) {
+			assignments = append(assignments, assignOption(name+"."+optionName(sub), sub, v.Message().Get(sub))...)
+		}
+		return assignments
+	default:
+		return []string{name + " = " + formatOptionValue(fd, v)}
+	}
+}
+
+// isFlat returns whether the message has fields set and none of them (recursively) are repeated or maps
+func isFlat(msg protoreflect.Message) bool {
+	fields := setFields(msg)
+	for _, fd := range fields {
+		if fd.IsList() || fd.IsMap() || fd.Message() != nil && !isFlat(msg.Get(fd).Message()) {
+			return false
+		}
+	}
+
+	return len(fields) > 0
+}
+
+// setFields returns the fields set on the message, standard fields first and then extensions, each ordered by number
+func setFields(msg protoreflect.Message) []protoreflect.FieldDescriptor {
+	fields := make([]protoreflect.FieldDescriptor, 0)
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].IsExtension() != fields[j].IsExtension() {
+			return !fields[i].IsExtension()
+		}
+		return fields[i].Number() < fields[j].Number()
+	})
+
+	return fields
+}
+
+// optionName returns the name of the option in an option statement: extensions are written in parentheses
+func optionName(fd protoreflect.FieldDescriptor) string {
+	if fd.IsExtension() {
+		return "(" + string(fd.FullName()) + ")"
+	}
+
+	return string(fd.Name())
+}
+
+// formatOptionValue formats a singular value as a constant of the proto language, or as a text format aggregate for
+// messages
+func formatOptionValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(v.Bool())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.StringKind:
+		return quoteProto([]byte(v.String()))
+	case protoreflect.BytesKind:
+		return quoteProto(v.Bytes())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return formatFloat(v.Float())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return formatAggregate(v.Message())
+	default:
+		return v.String()
+	}
+}
+
+// formatAggregate formats the message as a text format aggregate (e.g. `{ name: "x" ids: [1, 2] }`)
+func formatAggregate(msg protoreflect.Message) string {
+	parts := make([]string, 0)
+	for _, fd := range setFields(msg) {
+		name := string(fd.Name())
+		if fd.IsExtension() {
+			name = "[" + string(fd.FullName()) + "]"
+		} else if fd.Kind() == protoreflect.GroupKind {
+			name = string(fd.Message().Name())
+		}
+
+		v := msg.Get(fd)
+		switch {
+		case fd.IsList():
+			elems := make([]string, v.List().Len())
+			for i := range elems {
+				elems[i] = formatOptionValue(fd, v.List().Get(i))
+			}
+			parts = append(parts, name+": ["+strings.Join(elems, ", ")+"]")
+		case fd.IsMap():
+			entries := make([]string, 0, v.Map().Len())
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				entries = append(entries, "{ key: "+formatOptionValue(fd.MapKey(), k.Value())+
+					" value: "+formatOptionValue(fd.MapValue(), mv)+" }")
+				return true
+			})
+			sort.Strings(entries)
+			parts = append(parts, name+": ["+strings.Join(entries, ", ")+"]")
+		default:
+			parts = append(parts, name+": "+formatOptionValue(fd, v))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "{}"
+	}
+
+	return "{ " + strings.Join(parts, " ") + " }"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+// quoteProto quotes the bytes as a proto string literal, escaping quotes, backslashes and non-printable bytes (in octal)
+// so that arbitrary bytes round-trip
+func quoteProto(b []byte) string {
+	s := new(strings.Builder)
+	s.WriteByte('"')
+	for _, c := range b {
+		switch {
+		case c == '"' || c == '\\':
+			s.WriteByte('\\')
+			s.WriteByte(c)
+		case c == '\n':
+			s.WriteString(`\n`)
+		case c == '\r':
+			s.WriteString(`\r`)
+		case c == '\t':
+			s.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(s, `\%03o`, c)
+		default:
+			s.WriteByte(c)
+		}
+	}
+	s.WriteByte('"')
+
+	return s.String()
+}
@@ -0,0 +1,78 @@
+package protokit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGenerateProtoSource(t *testing.T) {
+	tests := []struct {
+		fixture, file, golden string
+	}{
+		{fixture: "shop", file: "shop/shop.proto", golden: "shop.proto"},
+		{fixture: "legacy", file: "legacy/legacy.proto", golden: "legacy.proto"},
+		{fixture: "editions", file: "ed/ed.proto", golden: "editions.proto"},
+		{fixture: "options", file: "user/user.proto", golden: "user.proto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			got, err := GenerateProtoSource(parseFixtureFile(t, tt.fixture, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", "source", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("GenerateProtoSource() differs from %s (run with -update to regenerate it):\n%s", path, got)
+			}
+
+			// map entries are written with the map syntax only, even when they're part of the nested messages
+			withEntries, err := GenerateProtoSource(parseFixtureFile(t, tt.fixture, tt.file, IncludeMapEntries()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if withEntries != got {
+				t.Errorf("GenerateProtoSource() differs with IncludeMapEntries:\n%s", withEntries)
+			}
+		})
+	}
+}
+
+func TestGenerateProtoSourceTopLevelMapEntry(t *testing.T) {
+	req := loadFixture(t, "shop", "shop/shop.proto")
+	for _, fd := range req.GetProtoFile() {
+		if fd.GetName() == "shop/shop.proto" {
+			entry := proto.Clone(fd.GetMessageType()[0].GetNestedType()[0]).(*descriptorpb.DescriptorProto)
+			fd.MessageType = append(fd.MessageType, entry)
+		}
+	}
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if f.GetName() != "shop/shop.proto" {
+			continue
+		}
+		if _, err := GenerateProtoSource(f); err == nil || !strings.Contains(err.Error(), ".shop.CountsEntry") {
+			t.Errorf("GenerateProtoSource() error = %v, want the top-level map entry .shop.CountsEntry", err)
+		}
+	}
+}
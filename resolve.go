@@ -0,0 +1,193 @@
+package protokit
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// resolveTypes links the message and enum types referenced by the fields and methods of the file to their parsed
+// descriptors (rather than copies), so nested types keep their `Parent` chain. Types are looked up in the files visible
+// from the file (see `visibleFiles`).
+func resolveTypes(f *PKFileDescriptor) {
+	resolveExtensionTypes(f, f.GetExtensions())
+	for _, m := range f.GetMessages() {
+		resolveMessageTypes(f, m)
+	}
+
+	for _, svc := range f.GetServices() {
+		for _, m := range svc.GetMethods() {
+			m.InputType = findMessage(f, m.GetInputTypeName())
+			m.OutputType = findMessage(f, m.GetOutputTypeName())
+
+			if m.InputType != nil {
+				m.InputType.requestOf = append(m.InputType.requestOf, m)
+			}
+			if m.OutputType != nil {
+				m.OutputType.responseOf = append(m.OutputType.responseOf, m)
+			}
+		}
+	}
+}
+
+func resolveMessageTypes(f *PKFileDescriptor, m *PKDescriptor) {
+	for _, fd := range m.GetMessageFields() {
+		switch fd.ProtoDesc().GetType() {
+		case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE:
+			fd.MessageType = findMessage(f, fd.GetTypeName())
+		case descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+			fd.MessageType = findGroupMessage(f, fd)
+		case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+			fd.EnumType = findEnum(f, fd.GetTypeName())
+		}
+	}
+
+	resolveExtensionTypes(f, m.GetExtensions())
+	for _, nested := range m.GetMessages() {
+		resolveMessageTypes(f, nested)
+	}
+
+	// the value of a map may be a message or enum defined in another file, which `GetMapValue` must resolve too. Entries
+	// are only part of the nested messages with `IncludeMapEntries`, in which case resolving them again is harmless.
+	for _, entry := range m.GetMapEntries() {
+		resolveMessageTypes(f, entry)
+	}
+}
+
+func resolveExtensionTypes(f *PKFileDescriptor, exts []*PKExtensionDescriptor) {
+	for _, ext := range exts {
+		ext.ExtendedMessage = findMessage(f, ext.GetExtendee())
+	}
+}
+
+// findGroupMessage resolves the message of a group field. Groups declare an implicit nested message named after the
+// field with its first letter capitalized (the field name being the lower-cased message name), which is used when the
+// field doesn't carry a type name.
+func findGroupMessage(f *PKFileDescriptor, fd *PKFieldDescriptor) *PKDescriptor {
+	if fd.GetTypeName() != "" {
+		return findMessage(f, fd.GetTypeName())
+	}
+
+	for _, m := range fd.GetMessage().GetMessages() {
+		if strings.ToLower(m.GetName()) == fd.GetName() {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// visibleFiles returns the files whose types can be referenced from the supplied file: the file itself, its direct
+// dependencies, and any files those dependencies re-export through (possibly chained) public imports
+func visibleFiles(f *PKFileDescriptor) []*PKFileDescriptor {
+	files := []*PKFileDescriptor{f}
+	seen := map[*PKFileDescriptor]bool{f: true}
+
+	var addPublic func(dep *PKFileDescriptor)
+	addPublic = func(dep *PKFileDescriptor) {
+		if dep == nil || seen[dep] {
+			return
+		}
+
+		seen[dep] = true
+		files = append(files, dep)
+		for _, pub := range dep.GetPublicDependencies() {
+			addPublic(pub)
+		}
+	}
+
+	for _, dep := range f.GetDependencies() {
+		addPublic(dep)
+	}
+
+	return files
+}
+
+func findMessage(f *PKFileDescriptor, fullName string) *PKDescriptor {
+	for _, file := range visibleFiles(f) {
+		if m := lookupMessage(file.GetMessages(), fullName); m != nil {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func findEnum(f *PKFileDescriptor, fullName string) *PKEnumDescriptor {
+	for _, file := range visibleFiles(f) {
+		if e := lookupEnum(file.GetEnums(), file.GetMessages(), fullName); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// lookupMessage returns the parsed message (nested or not) with the specified full name. Only messages whose name is a
+// prefix of the full name are descended into.
+func lookupMessage(msgs []*PKDescriptor, fullName string) *PKDescriptor {
+	for _, m := range msgs {
+		if m.GetFullName() == fullName {
+			return m
+		}
+
+		if strings.HasPrefix(fullName, m.GetFullName()+".") {
+			if entry := lookupMessage(m.GetMapEntries(), fullName); entry != nil {
+				return entry
+			}
+
+			return lookupMessage(m.GetMessages(), fullName)
+		}
+	}
+
+	return nil
+}
+
+// lookupEnum returns the parsed enum (nested or not) with the specified full name
+func lookupEnum(enums []*PKEnumDescriptor, msgs []*PKDescriptor, fullName string) *PKEnumDescriptor {
+	for _, e := range enums {
+		if e.GetFullName() == fullName {
+			return e
+		}
+	}
+
+	for _, m := range msgs {
+		if strings.HasPrefix(fullName, m.GetFullName()+".") {
+			return lookupEnum(m.GetEnums(), m.GetMessages(), fullName)
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns the descriptor (message, enum, service, method or field) with the specified full name across the
+// files, e.g. the name of a `protoreflect.Descriptor`. The second return value is false if it wasn't found
+func Resolve(files []*PKFileDescriptor, name protoreflect.FullName) (Descriptor, bool) {
+	fullName := "." + string(name)
+
+	var found Descriptor
+	for _, f := range files {
+		if pkg := f.GetPackage(); pkg != "" && !strings.HasPrefix(string(name), pkg+".") {
+			continue
+		}
+
+		Walk(f, func(d Descriptor) bool {
+			if found != nil {
+				return false
+			}
+
+			if _, isValue := d.(*PKEnumValueDescriptor); !isValue && d.GetFullName() == fullName {
+				found = d
+			}
+
+			return found == nil
+		})
+
+		if found != nil {
+			return found, true
+		}
+	}
+
+	return nil, false
+}
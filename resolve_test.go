@@ -0,0 +1,162 @@
+package protokit
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestResolveNestedTypes(t *testing.T) {
+	files := parseFixture(t, "shop")
+	item := files["shop/shop.proto"].GetMessage("Item")
+	detail := item.GetMessage("Detail")
+
+	if got := item.GetMessageField("detail").GetMessageType(); got != detail {
+		t.Fatalf("detail.GetMessageType() = %p, want the parsed Item.Detail %p", got, detail)
+	}
+	if got := detail.GetParent(); got != item {
+		t.Errorf("Item.Detail.GetParent() = %v, want Item", got.GetFullName())
+	}
+
+	note := detail.GetMessageField("note").GetMessageType()
+	if note != detail.GetMessage("Note") {
+		t.Fatalf("note.GetMessageType() = %p, want the parsed Item.Detail.Note", note)
+	}
+	if got := note.GetParent().GetParent(); got != item {
+		t.Errorf("Item.Detail.Note parent chain ends at %q, want .shop.Item", got.GetFullName())
+	}
+
+	level := detail.GetMessageField("level").GetEnumType()
+	if level != detail.GetEnum("Level") {
+		t.Fatalf("level.GetEnumType() = %p, want the parsed Item.Detail.Level", level)
+	}
+	if got := level.GetParent(); got != detail {
+		t.Errorf("Item.Detail.Level.GetParent() = %v, want Item.Detail", got.GetFullName())
+	}
+
+	// types of other files resolve to the descriptors parsed for those files
+	money := files["common/money.proto"].GetMessage("Money")
+	if got := item.GetMessageField("price").GetMessageType(); got != money {
+		t.Errorf("price.GetMessageType() = %p, want the parsed common.Money %p", got, money)
+	}
+}
+
+func TestResolvePublicImport(t *testing.T) {
+	files := parseFixture(t, "shop")
+	shop := files["shop/shop.proto"]
+	money := files["common/money.proto"].GetMessage("Money")
+
+	// shop/shop.proto only sees common/money.proto through the public import of common/reexport.proto
+	for _, dep := range shop.GetDependencies() {
+		if dep.GetName() == "common/money.proto" {
+			t.Fatal("common/money.proto is a direct dependency of shop/shop.proto")
+		}
+	}
+
+	item := shop.GetMessage("Item")
+	if got := item.GetMessageField("price").GetMessageType(); got != money {
+		t.Errorf("price.GetMessageType() = %v, want .common.Money", got.GetFullName())
+	}
+	if got := item.GetMessageField("prices").GetMapValue().GetMessageType(); got != money {
+		t.Errorf("prices.GetMapValue().GetMessageType() = %v, want .common.Money", got.GetFullName())
+	}
+
+	names := make(map[string]bool)
+	for _, f := range visibleFiles(shop) {
+		names[f.GetName()] = true
+	}
+	for _, name := range []string{"shop/shop.proto", "common/reexport.proto", "common/money.proto"} {
+		if !names[name] {
+			t.Errorf("visibleFiles() = %v, want %s among them", names, name)
+		}
+	}
+}
+
+func TestResolveGroup(t *testing.T) {
+	f := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+	order := f.GetMessage("Order")
+	shipping := order.GetMessage("Shipping")
+
+	field := order.GetMessageField("shipping")
+	if !field.IsGroup() {
+		t.Error("shipping.IsGroup() = false, want true")
+	}
+	if order.GetMessageField("status").IsGroup() {
+		t.Error("status.IsGroup() = true, want false")
+	}
+	if got := field.GetMessageType(); got != shipping {
+		t.Errorf("shipping.GetMessageType() = %v, want .legacy.Order.Shipping", got.GetFullName())
+	}
+	if got := field.GetMessageType().GetMessageField("address"); got == nil {
+		t.Error("the group message has no address field")
+	}
+
+	// without a type name, the group's message is found by its capitalized name
+	untyped := *field
+	untyped.desc = proto.Clone(field.ProtoDesc()).(*descriptorpb.FieldDescriptorProto)
+	untyped.desc.TypeName = nil
+	if got := findGroupMessage(f, &untyped); got != shipping {
+		t.Errorf("findGroupMessage() = %v without a type name, want .legacy.Order.Shipping", got.GetFullName())
+	}
+}
+
+func TestResolve(t *testing.T) {
+	files, err := ParseCodeGenRequestAllFiles(loadFixture(t, "shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]*PKFileDescriptor)
+	for _, f := range files {
+		byName[f.GetName()] = f
+	}
+	shop := byName["shop/shop.proto"]
+	item := shop.GetMessage("Item")
+
+	tests := []struct {
+		name protoreflect.FullName
+		want Descriptor
+	}{
+		{name: "shop.Item", want: item},
+		{name: "shop.Item.Detail.Level", want: item.GetMessage("Detail").GetEnum("Level")},
+		{name: "shop.Item.item_id", want: item.GetMessageField("item_id")},
+		{name: "shop.Shop", want: shop.GetService("Shop")},
+		{name: "shop.Shop.GetItem", want: shop.GetService("Shop").GetNamedMethod("GetItem")},
+		{name: "common.Money", want: byName["common/money.proto"].GetMessage("Money")},
+	}
+
+	for _, tt := range tests {
+		got, ok := Resolve(files, tt.name)
+		if !ok || got != tt.want {
+			t.Errorf("Resolve(%q) = %v, %t, want %v", tt.name, got, ok, tt.want.GetFullName())
+		}
+	}
+
+	// enum values are scoped to the enum's parent, so they aren't resolved
+	for _, name := range []protoreflect.FullName{"shop.Missing", "shop.Item.Detail.Level.LEVEL_HIGH", "Item", ""} {
+		if got, ok := Resolve(files, name); ok {
+			t.Errorf("Resolve(%q) = %v, want not found", name, got.GetFullName())
+		}
+	}
+}
+
+func TestResolveImportedMapValue(t *testing.T) {
+	for _, opts := range [][]ParseOption{nil, {IncludeMapEntries()}} {
+		files := parseFixture(t, "shop", opts...)
+		money := files["common/money.proto"].GetMessage("Money")
+
+		// map<string, common.Money> prices, with common.Money defined in another file
+		prices := files["shop/shop.proto"].GetMessage("Item").GetMessageField("prices")
+		if !prices.IsMap() {
+			t.Fatalf("prices.IsMap() = false")
+		}
+		if got := prices.GetMapValue().GetMessageType(); got != money {
+			t.Errorf("with %d options: prices.GetMapValue().GetMessageType() = %v, want the parsed common.Money",
+				len(opts), got.GetFullName())
+		}
+		if got := prices.GetMapKey().GetMessageType(); got != nil {
+			t.Errorf("with %d options: prices.GetMapKey().GetMessageType() = %v, want nil", len(opts), got.GetFullName())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package protokit
+
+// FileStats holds the number of descriptors of each kind declared in a file
+type FileStats struct {
+	Messages   int
+	Enums      int
+	EnumValues int
+	Services   int
+	Methods    int
+	Fields     int
+	Extensions int
+}
+
+// Stats returns the number of descriptors of each kind declared in the file, including those nested within messages.
+// The descriptors are counted as visited by `Walk`, so map entries are only counted when parsed with `IncludeMapEntries`.
+func (f *PKFileDescriptor) Stats() FileStats {
+	var stats FileStats
+	Walk(f, func(d Descriptor) bool {
+		switch d.(type) {
+		case *PKDescriptor:
+			stats.Messages++
+		case *PKEnumDescriptor:
+			stats.Enums++
+		case *PKEnumValueDescriptor:
+			stats.EnumValues++
+		case *PKServiceDescriptor:
+			stats.Services++
+		case *PKMethodDescriptor:
+			stats.Methods++
+		case *PKFieldDescriptor:
+			stats.Fields++
+		case *PKExtensionDescriptor:
+			stats.Extensions++
+		}
+
+		return true
+	})
+
+	return stats
+}
@@ -0,0 +1,37 @@
+package protokit
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name string
+		file *PKFileDescriptor
+		want FileStats
+	}{
+		{
+			name: "shop",
+			file: parseFixtureFile(t, "shop", "shop/shop.proto"),
+			want: FileStats{Messages: 6, Enums: 2, EnumValues: 4, Services: 1, Methods: 4, Fields: 20},
+		},
+		{
+			// the two map entries and their key and value fields
+			name: "shop with map entries",
+			file: parseFixtureFile(t, "shop", "shop/shop.proto", IncludeMapEntries()),
+			want: FileStats{Messages: 8, Enums: 2, EnumValues: 4, Services: 1, Methods: 4, Fields: 24},
+		},
+		{
+			// Order, its Shipping group and Declared, with the nested note extension and the top-level ones
+			name: "legacy",
+			file: parseFixtureFile(t, "legacy", "legacy/legacy.proto"),
+			want: FileStats{Messages: 3, Enums: 1, EnumValues: 3, Fields: 7, Extensions: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.file.Stats(); got != tt.want {
+			t.Errorf("%s: Stats() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -11,16 +11,23 @@ import (
 	"google.golang.org/protobuf/types/dynamicpb"
 )
 
+// common holds the fields shared by all descriptors.
+//
+// The accessors of the descriptors (e.g. `GetName`, `GetFullName`, `GetComments` or `GetMessages`), and the lookups built
+// on them, can be called on a nil descriptor and return zero values, so chained lookups such as
+// `file.GetMessage("Foo").GetMessageField("bar").GetFullName()` don't panic. The accessors of common are overridden by
+// each descriptor type for that purpose (see nilsafe.go).
 type common struct {
 	file     *PKFileDescriptor
 	path     string
+	index    int
 	LongName string
 	FullName string
 
 	OptionExtensions map[string]interface{}
 }
 
-func newCommon(f *PKFileDescriptor, path, longName string) common {
+func newCommon(f *PKFileDescriptor, path, longName string, index int) common {
 	fn := longName
 	if !strings.HasPrefix(fn, ".") {
 		fn = fmt.Sprintf("%s.%s", f.GetPackage(), longName)
@@ -32,31 +39,101 @@ func newCommon(f *PKFileDescriptor, path, longName string) common {
 	return common{
 		file:     f,
 		path:     path,
+		index:    index,
 		LongName: longName,
 		FullName: fn,
 	}
 }
 
 // GetFile returns the PKFileDescriptor that contains this object
-func (c *common) GetFile() *PKFileDescriptor { return c.file }
+func (c *common) GetFile() *PKFileDescriptor {
+	if c == nil {
+		return nil
+	}
+
+	return c.file
+}
 
 // GetPackage returns the package this object is in
-func (c *common) GetPackage() string { return c.file.GetPackage() }
+func (c *common) GetPackage() string { return c.GetFile().GetPackage() }
 
 // GetLongName returns the name prefixed with the dot-separated parent descriptor's name (if any)
-func (c *common) GetLongName() string { return c.LongName }
+func (c *common) GetLongName() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.LongName
+}
 
 // GetFullName returns the `LongName` prefixed with the package this object is in
-func (c *common) GetFullName() string { return c.FullName }
+func (c *common) GetFullName() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.FullName
+}
+
+// GetIndex returns the position of this object among its siblings of the same kind (e.g. the fields of a message), in
+// declaration order. Synthetic map entry messages are counted among nested messages
+func (c *common) GetIndex() int {
+	if c == nil {
+		return 0
+	}
+
+	return c.index
+}
 
 // IsProto3 returns whether or not this is a proto3 object
-func (c *common) IsProto3() bool { return c.file.GetSyntax() == "proto3" }
+func (c *common) IsProto3() bool { return c.GetFile().GetSyntax() == "proto3" }
 
 // GetOptionExtensions returns the options defined for this object
-func (c *common) GetOptionExtensions() map[string]interface{} { return c.OptionExtensions }
+func (c *common) GetOptionExtensions() map[string]interface{} {
+	if c == nil {
+		return nil
+	}
+
+	return c.OptionExtensions
+}
+
+// GetPath returns the SourceCodeInfo path of this object, with its elements joined by a "." character (e.g. `4.0.2.1`
+// for the second field of the first message). See `ParseComments` for details.
+func (c *common) GetPath() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.path
+}
+
+// SourcePath returns a description of this object for error messages, made of its full name (without the leading dot)
+// and the location it was declared at, e.g. `pkg.Foo.bar (foo.proto:12:3)`. The line and column are omitted when the
+// file has no source code info. An empty string is returned for a nil descriptor.
+func (c *common) SourcePath() string {
+	if c == nil {
+		return ""
+	}
+
+	name := strings.TrimPrefix(c.GetFullName(), ".")
+	if loc, ok := c.file.locations[c.path]; ok && len(loc.GetSpan()) >= 2 {
+		return fmt.Sprintf("%s (%s:%d:%d)", name, c.file.GetName(), loc.GetSpan()[0]+1, loc.GetSpan()[1]+1)
+	}
+
+	return fmt.Sprintf("%s (%s)", name, c.file.GetName())
+}
+
+// SameFile returns whether or not the descriptors are declared in the same file, e.g. to decide whether a referenced type
+// needs to be qualified or imported
+func SameFile(a, b interface{ GetFile() *PKFileDescriptor }) bool {
+	return a.GetFile() != nil && a.GetFile() == b.GetFile()
+}
 
-func getOptions(options proto.Message) (m map[string]interface{}) {
-	protoregistry.GlobalTypes.RangeExtensions(func(extensionType protoreflect.ExtensionType) bool {
+func getOptions(types *protoregistry.Types, options proto.Message) (m map[string]interface{}) {
+	if types == nil {
+		types = protoregistry.GlobalTypes
+	}
+	types.RangeExtensions(func(extensionType protoreflect.ExtensionType) bool {
 		if extensionType.TypeDescriptor().ContainingMessage().FullName() ==
 			options.ProtoReflect().Descriptor().FullName() &&
 			options.ProtoReflect().Has(extensionType.TypeDescriptor()) {
@@ -75,7 +152,7 @@ func getOptions(options proto.Message) (m map[string]interface{}) {
 }
 
 func (c *common) setOptions(options proto.Message) {
-	if opts := getOptions(options); len(opts) > 0 {
+	if opts := getOptions(c.file.optionTypes, options); len(opts) > 0 {
 		if c.OptionExtensions == nil {
 			c.OptionExtensions = opts
 			return
@@ -86,6 +163,13 @@ func (c *common) setOptions(options proto.Message) {
 	}
 }
 
+// An ImportPath describes a single `import` statement of a proto file
+type ImportPath struct {
+	Path     string
+	IsPublic bool
+	IsWeak   bool
+}
+
 // An PKImportedDescriptor describes a type that was imported by a PKFileDescriptor.
 type PKImportedDescriptor struct {
 	common
@@ -93,8 +177,13 @@ type PKImportedDescriptor struct {
 
 // A PKFileDescriptor describes a single proto file with all of its messages, enums, services, etc.
 type PKFileDescriptor struct {
-	comments Comments
-	desc     *descriptorpb.FileDescriptorProto
+	comments  Comments
+	locations map[string]*descriptorpb.SourceCodeInfo_Location
+	desc      *descriptorpb.FileDescriptorProto
+	types     *protoregistry.Types
+
+	// optionTypes is the registry the option extensions are resolved from (`protoregistry.GlobalTypes` if nil)
+	optionTypes *protoregistry.Types
 
 	PackageComments *Comment
 	SyntaxComments  *Comment
@@ -106,54 +195,296 @@ type PKFileDescriptor struct {
 	Services           []*PKServiceDescriptor
 	Dependencies       []*PKFileDescriptor
 	PublicDependencies []*PKFileDescriptor
+	WeakDependencies   []*PKFileDescriptor
 
 	OptionExtensions map[string]interface{}
 
 	FileDescriptor   protoreflect.FileDescriptor
 	IsFileToGenerate bool
+	CompilerVersion  *Version
 }
 
-func (f *PKFileDescriptor) ProtoDesc() *descriptorpb.FileDescriptorProto { return f.desc }
+func (f *PKFileDescriptor) ProtoDesc() *descriptorpb.FileDescriptorProto {
+	if f == nil {
+		return nil
+	}
+
+	return f.desc
+}
 
 func (f *PKFileDescriptor) GetName() string    { return f.ProtoDesc().GetName() }
 func (f *PKFileDescriptor) GetPackage() string { return f.ProtoDesc().GetPackage() }
 func (f *PKFileDescriptor) GetSyntax() string  { return f.ProtoDesc().GetSyntax() }
 
-func (f *PKFileDescriptor) GetDependencies() []*PKFileDescriptor       { return f.Dependencies }
-func (f *PKFileDescriptor) GetPublicDependencies() []*PKFileDescriptor { return f.PublicDependencies }
+func (f *PKFileDescriptor) GetDependencies() []*PKFileDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.Dependencies
+}
+func (f *PKFileDescriptor) GetPublicDependencies() []*PKFileDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.PublicDependencies
+}
+
+// GetWeakDependencies returns the weakly imported files (`import weak`). Weak imports that weren't supplied in the request
+// are omitted, as they are from `GetDependencies`
+func (f *PKFileDescriptor) GetWeakDependencies() []*PKFileDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.WeakDependencies
+}
+
+// IsProto2 returns whether or not this file is a proto2 file (an empty syntax defaults to proto2)
+func (f *PKFileDescriptor) IsProto2() bool { return f.GetSyntax() == "" || f.GetSyntax() == "proto2" }
+
+// Syntax describes the syntax of a proto file
+type Syntax int
+
+const (
+	// SyntaxUnknown is used for files declaring a syntax that isn't recognized
+	SyntaxUnknown Syntax = iota
+	// SyntaxProto2 is used for `syntax = "proto2"` files and files that don't declare a syntax
+	SyntaxProto2
+	// SyntaxProto3 is used for `syntax = "proto3"` files
+	SyntaxProto3
+	// SyntaxEditions is used for files declaring an edition (e.g. `edition = "2023"`)
+	SyntaxEditions
+)
+
+// String returns the syntax as it's declared in proto files ("proto2", "proto3" or "editions"), or "unknown"
+func (s Syntax) String() string {
+	switch s {
+	case SyntaxProto2:
+		return "proto2"
+	case SyntaxProto3:
+		return "proto3"
+	case SyntaxEditions:
+		return "editions"
+	default:
+		return "unknown"
+	}
+}
+
+// GetSyntaxEnum returns the syntax of this file as a `Syntax`. Files without a syntax are proto2 files.
+func (f *PKFileDescriptor) GetSyntaxEnum() Syntax {
+	switch f.GetSyntax() {
+	case "", "proto2":
+		return SyntaxProto2
+	case "proto3":
+		return SyntaxProto3
+	case "editions":
+		return SyntaxEditions
+	default:
+		return SyntaxUnknown
+	}
+}
 
 // IsProto3 returns whether or not this file is a proto3 file
 func (f *PKFileDescriptor) IsProto3() bool { return f.GetSyntax() == "proto3" }
 
+// IsEditions returns whether or not this file uses protobuf editions (i.e. `edition = "2023"`)
+func (f *PKFileDescriptor) IsEditions() bool { return f.GetSyntax() == "editions" }
+
+// GetEdition returns the edition of this file. For proto2 and proto3 files, `EDITION_PROTO2` and `EDITION_PROTO3` are
+// returned respectively
+func (f *PKFileDescriptor) GetEdition() descriptorpb.Edition {
+	switch {
+	case f.IsEditions():
+		return f.ProtoDesc().GetEdition()
+	case f.IsProto3():
+		return descriptorpb.Edition_EDITION_PROTO3
+	default:
+		return descriptorpb.Edition_EDITION_PROTO2
+	}
+}
+
+// GetLanguageVersion returns a normalized version string for the file: "proto2", "proto3" or "editions/<edition>" (e.g.
+// "editions/2023")
+func (f *PKFileDescriptor) GetLanguageVersion() string {
+	switch {
+	case f.IsEditions():
+		return "editions/" + strings.TrimPrefix(f.GetEdition().String(), "EDITION_")
+	case f.IsProto3():
+		return "proto3"
+	default:
+		return "proto2"
+	}
+}
+
 // GetPackageComments returns the file's package comments
-func (f *PKFileDescriptor) GetPackageComments() *Comment { return f.PackageComments }
+func (f *PKFileDescriptor) GetPackageComments() *Comment {
+	if f == nil {
+		return nil
+	}
+
+	return f.PackageComments
+}
 
 // GetSyntaxComments returns the file's syntax comments
-func (f *PKFileDescriptor) GetSyntaxComments() *Comment { return f.SyntaxComments }
+func (f *PKFileDescriptor) GetSyntaxComments() *Comment {
+	if f == nil {
+		return nil
+	}
+
+	return f.SyntaxComments
+}
+
+// GetCommentForPath returns the comment for the specified SourceCodeInfo path. The path elements are joined by a "."
+// character (e.g. `4.0.2.1` for the second field of the first message). An empty comment is returned if none was found
+func (f *PKFileDescriptor) GetCommentForPath(path string) *Comment {
+	if f == nil {
+		return Comments(nil).Get(path)
+	}
+
+	return f.comments.Get(path)
+}
 
 // GetEnums returns the top-level enumerations defined in this file
-func (f *PKFileDescriptor) GetEnums() []*PKEnumDescriptor { return f.Enums }
+func (f *PKFileDescriptor) GetEnums() []*PKEnumDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.Enums
+}
 
 // GetExtensions returns the top-level (file) extensions defined in this file
-func (f *PKFileDescriptor) GetExtensions() []*PKExtensionDescriptor { return f.Extensions }
+func (f *PKFileDescriptor) GetExtensions() []*PKExtensionDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.Extensions
+}
+
+// GetExtensionsByExtendee returns all the extensions declared in the file, both top-level and nested within messages,
+// grouped by the fully-qualified name of the message they extend (e.g. `.pkg.Msg`). The extensions of each group are in
+// the order they're visited by `Walk`.
+func (f *PKFileDescriptor) GetExtensionsByExtendee() map[string][]*PKExtensionDescriptor {
+	exts := make(map[string][]*PKExtensionDescriptor)
+	for _, ext := range f.GetAllExtensions() {
+		extendee := ext.GetExtendee()
+		if !strings.HasPrefix(extendee, ".") {
+			extendee = "." + extendee
+		}
+		exts[extendee] = append(exts[extendee], ext)
+	}
+
+	return exts
+}
+
+// GetAllExtensions returns all the extensions declared in the file: the top-level ones first, followed by those nested
+// within messages in the order they're visited by `Walk`. This isn't the order of the source file, which descriptors
+// don't keep across kinds of declarations: an extension nested within a message declared before the top-level
+// extensions still comes after them.
+func (f *PKFileDescriptor) GetAllExtensions() []*PKExtensionDescriptor {
+	exts := make([]*PKExtensionDescriptor, 0)
+	Walk(f, func(d Descriptor) bool {
+		if ext, ok := d.(*PKExtensionDescriptor); ok {
+			exts = append(exts, ext)
+		}
+
+		return true
+	})
+
+	return exts
+}
+
+// GetImports returns the top-level messages (except map entries), enums and extensions of the files imported by this
+// file, sorted by full name and without duplicates so that generated code doesn't depend on the import order
+func (f *PKFileDescriptor) GetImports() []*PKImportedDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.Imports
+}
 
-// GetImports returns the proto files imported by this file
-func (f *PKFileDescriptor) GetImports() []*PKImportedDescriptor { return f.Imports }
+// GetImportPaths returns the import statements of this file in declaration order
+func (f *PKFileDescriptor) GetImportPaths() []ImportPath {
+	public := make(map[int32]bool)
+	for _, idx := range f.ProtoDesc().GetPublicDependency() {
+		public[idx] = true
+	}
+
+	weak := make(map[int32]bool)
+	for _, idx := range f.ProtoDesc().GetWeakDependency() {
+		weak[idx] = true
+	}
+
+	paths := make([]ImportPath, len(f.ProtoDesc().GetDependency()))
+	for i, dep := range f.ProtoDesc().GetDependency() {
+		paths[i] = ImportPath{
+			Path:     dep,
+			IsPublic: public[int32(i)],
+			IsWeak:   weak[int32(i)],
+		}
+	}
+
+	return paths
+}
 
 // GetMessages returns the top-level messages defined in this file
-func (f *PKFileDescriptor) GetMessages() []*PKDescriptor { return f.Messages }
+func (f *PKFileDescriptor) GetMessages() []*PKDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.Messages
+}
 
 // GetServices returns the services defined in this file
-func (f *PKFileDescriptor) GetServices() []*PKServiceDescriptor { return f.Services }
+func (f *PKFileDescriptor) GetServices() []*PKServiceDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.Services
+}
 
 // GetOptionExtensions returns the file-level options defined in this file
-func (f *PKFileDescriptor) GetOptionExtensions() map[string]interface{} { return f.OptionExtensions }
+func (f *PKFileDescriptor) GetOptionExtensions() map[string]interface{} {
+	if f == nil {
+		return nil
+	}
+
+	return f.OptionExtensions
+}
 
 // GetFileDescriptor returns the underlying `protoreflect.FileDescriptor`
-func (f *PKFileDescriptor) GetFileDescriptor() protoreflect.FileDescriptor { return f.FileDescriptor }
+func (f *PKFileDescriptor) GetFileDescriptor() protoreflect.FileDescriptor {
+	if f == nil {
+		return nil
+	}
+
+	return f.FileDescriptor
+}
 
 // GetIsFileToGenerate returns whether or not this file is to be generated
-func (f *PKFileDescriptor) GetIsFileToGenerate() bool { return f.IsFileToGenerate }
+func (f *PKFileDescriptor) GetIsFileToGenerate() bool {
+	if f == nil {
+		return false
+	}
+
+	return f.IsFileToGenerate
+}
+
+// GetCompilerVersion returns the version of the compiler that produced the request the file was parsed from (returns
+// `nil` if the request didn't specify it)
+func (f *PKFileDescriptor) GetCompilerVersion() *Version {
+	if f == nil {
+		return nil
+	}
+
+	return f.CompilerVersion
+}
 
 // GetEnum returns the enumeration with the specified name (returns `nil` if not found)
 func (f *PKFileDescriptor) GetEnum(name string) *PKEnumDescriptor {
@@ -188,8 +519,67 @@ func (f *PKFileDescriptor) GetService(name string) *PKServiceDescriptor {
 	return nil
 }
 
+// GetFileOption returns the value of the file-level custom option with the specified full name (e.g. `my.pkg.policy`)
+// and whether or not it was set
+func (f *PKFileDescriptor) GetFileOption(fullName string) (interface{}, bool) {
+	val, ok := f.GetOptionExtensions()[strings.TrimPrefix(fullName, ".")]
+	return val, ok
+}
+
+// GetFileOptionString returns the file-level custom option with the specified full name as a string. The second return
+// value is false if the option isn't set or isn't a string
+func (f *PKFileDescriptor) GetFileOptionString(fullName string) (string, bool) {
+	val, _ := f.GetFileOption(fullName)
+	str, ok := val.(string)
+	return str, ok
+}
+
+// GetFileOptionBool returns the file-level custom option with the specified full name as a bool. The second return
+// value is false if the option isn't set or isn't a bool
+func (f *PKFileDescriptor) GetFileOptionBool(fullName string) (bool, bool) {
+	val, _ := f.GetFileOption(fullName)
+	b, ok := val.(bool)
+	return b, ok
+}
+
+// GetFileOptionInt returns the file-level custom option with the specified full name as an int64. Integer and enum
+// options are supported. The second return value is false if the option isn't set or isn't an integer
+func (f *PKFileDescriptor) GetFileOptionInt(fullName string) (int64, bool) {
+	val, _ := f.GetFileOption(fullName)
+	switch v := val.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case protoreflect.EnumNumber:
+		return int64(v), true
+	case protoreflect.Enum:
+		return int64(v.Number()), true
+	}
+
+	return 0, false
+}
+
+// GetFileOptionFloat returns the file-level custom option with the specified full name as a float64. The second return
+// value is false if the option isn't set or isn't a floating point number
+func (f *PKFileDescriptor) GetFileOptionFloat(fullName string) (float64, bool) {
+	val, _ := f.GetFileOption(fullName)
+	switch v := val.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
 func (f *PKFileDescriptor) setOptions(options proto.Message) {
-	if opts := getOptions(options); len(opts) > 0 {
+	if opts := getOptions(f.optionTypes, options); len(opts) > 0 {
 		if f.OptionExtensions == nil {
 			f.OptionExtensions = opts
 			return
@@ -210,19 +600,43 @@ type PKEnumDescriptor struct {
 }
 
 // ProtoDesc returns the underlying `EnumDescriptorProto`
-func (e *PKEnumDescriptor) ProtoDesc() *descriptorpb.EnumDescriptorProto { return e.desc }
+func (e *PKEnumDescriptor) ProtoDesc() *descriptorpb.EnumDescriptorProto {
+	if e == nil {
+		return nil
+	}
+
+	return e.desc
+}
 
 // GetName returns the name of the enum
 func (e *PKEnumDescriptor) GetName() string { return e.ProtoDesc().GetName() }
 
 // GetComments returns a description of this enum
-func (e *PKEnumDescriptor) GetComments() *Comment { return e.Comments }
+func (e *PKEnumDescriptor) GetComments() *Comment {
+	if e == nil {
+		return nil
+	}
+
+	return e.Comments
+}
 
 // GetParent returns the parent message (if any) that contains this enum
-func (e *PKEnumDescriptor) GetParent() *PKDescriptor { return e.Parent }
+func (e *PKEnumDescriptor) GetParent() *PKDescriptor {
+	if e == nil {
+		return nil
+	}
+
+	return e.Parent
+}
 
 // GetValues returns the available values for this enum
-func (e *PKEnumDescriptor) GetValues() []*PKEnumValueDescriptor { return e.Values }
+func (e *PKEnumDescriptor) GetValues() []*PKEnumValueDescriptor {
+	if e == nil {
+		return nil
+	}
+
+	return e.Values
+}
 
 // GetNamedValue returns the value with the specified name (returns `nil` if not found)
 func (e *PKEnumDescriptor) GetNamedValue(name string) *PKEnumValueDescriptor {
@@ -235,6 +649,18 @@ func (e *PKEnumDescriptor) GetNamedValue(name string) *PKEnumValueDescriptor {
 	return nil
 }
 
+// GetValueByNumber returns the value with the specified number (returns `nil` if not found). When several values share
+// the number (i.e. `allow_alias` is set), the first declared one is returned
+func (e *PKEnumDescriptor) GetValueByNumber(n int32) *PKEnumValueDescriptor {
+	for _, v := range e.GetValues() {
+		if v.ProtoDesc().GetNumber() == n {
+			return v
+		}
+	}
+
+	return nil
+}
+
 // An PKEnumValueDescriptor describes an enum value
 type PKEnumValueDescriptor struct {
 	common
@@ -244,36 +670,85 @@ type PKEnumValueDescriptor struct {
 }
 
 // ProtoDesc returns the underlying `EnumValueDescriptorProto`
-func (v *PKEnumValueDescriptor) ProtoDesc() *descriptorpb.EnumValueDescriptorProto { return v.desc }
+func (v *PKEnumValueDescriptor) ProtoDesc() *descriptorpb.EnumValueDescriptorProto {
+	if v == nil {
+		return nil
+	}
+
+	return v.desc
+}
 
 // GetName returns the name of the value
 func (v *PKEnumValueDescriptor) GetName() string { return v.ProtoDesc().GetName() }
 
 // GetComments returns a description of the value
-func (v *PKEnumValueDescriptor) GetComments() *Comment { return v.Comments }
+func (v *PKEnumValueDescriptor) GetComments() *Comment {
+	if v == nil {
+		return nil
+	}
+
+	return v.Comments
+}
+
+// GetNumber returns the number of the value
+func (v *PKEnumValueDescriptor) GetNumber() int32 { return v.ProtoDesc().GetNumber() }
+
+// IsDeprecated returns whether or not the value is marked with `deprecated = true`
+func (v *PKEnumValueDescriptor) IsDeprecated() bool {
+	return v.ProtoDesc().GetOptions().GetDeprecated()
+}
 
 // GetEnum returns the parent enumeration that contains this value
-func (v *PKEnumValueDescriptor) GetEnum() *PKEnumDescriptor { return v.Enum }
+func (v *PKEnumValueDescriptor) GetEnum() *PKEnumDescriptor {
+	if v == nil {
+		return nil
+	}
 
-// An PKExtensionDescriptor describes a protobuf extension. If it's a top-level extension it's parent will be `nil`
+	return v.Enum
+}
+
+// An PKExtensionDescriptor describes a protobuf extension. If it's a top-level extension it's parent will be `nil`.
+//
+// The long name of an extension is the name of the message it extends followed by its own name. The extended message is
+// named relative to the file's package when it belongs to that package or one of its subpackages (e.g. `Msg.ext` or
+// `bar.Msg.ext` in package `foo`), and fully-qualified otherwise (e.g. `.google.protobuf.FieldOptions.ext`). Packages
+// are compared by whole components, so `.foobar.Msg` isn't part of package `foo`. In a file without a package, every
+// extended message is named relative to the root (e.g. `foo.Msg.ext`). Either way, the full name is the fully-qualified
+// name of the extended message followed by the extension's name.
 type PKExtensionDescriptor struct {
 	common
 	desc                *descriptorpb.FieldDescriptorProto
 	Parent              *PKDescriptor
 	Comments            *Comment
 	ExtensionDescriptor protoreflect.ExtensionDescriptor
+	ExtendedMessage     *PKDescriptor
 }
 
 // ProtoDesc returns the underlying `desc`
-func (e *PKExtensionDescriptor) ProtoDesc() *descriptorpb.FieldDescriptorProto { return e.desc }
+func (e *PKExtensionDescriptor) ProtoDesc() *descriptorpb.FieldDescriptorProto {
+	if e == nil {
+		return nil
+	}
+
+	return e.desc
+}
 
 // GetExtensionDescriptor returns the underlying `protoreflect.ExtensionDescriptor`
 func (e *PKExtensionDescriptor) GetExtensionDescriptor() protoreflect.ExtensionDescriptor {
+	if e == nil {
+		return nil
+	}
+
 	return e.ExtensionDescriptor
 }
 
-// ExtensionType returns a new `protoreflect.ExtensionType` for this extension
+// ExtensionType returns a new `protoreflect.ExtensionType` for this extension (returns `nil` if there's no underlying
+// `protoreflect.ExtensionDescriptor`)
 func (e *PKExtensionDescriptor) ExtensionType() protoreflect.ExtensionType {
+	if e.GetExtensionDescriptor() == nil {
+		return nil
+	}
+
 	return dynamicpb.NewExtensionType(e.GetExtensionDescriptor())
 }
 
@@ -281,10 +756,51 @@ func (e *PKExtensionDescriptor) ExtensionType() protoreflect.ExtensionType {
 func (e *PKExtensionDescriptor) GetName() string { return e.ProtoDesc().GetName() }
 
 // GetComments returns a description of the extension
-func (e *PKExtensionDescriptor) GetComments() *Comment { return e.Comments }
+func (e *PKExtensionDescriptor) GetComments() *Comment {
+	if e == nil {
+		return nil
+	}
+
+	return e.Comments
+}
 
 // GetParent returns the descriptor that defined this extension (if any)
-func (e *PKExtensionDescriptor) GetParent() *PKDescriptor { return e.Parent }
+func (e *PKExtensionDescriptor) GetParent() *PKDescriptor {
+	if e == nil {
+		return nil
+	}
+
+	return e.Parent
+}
+
+// GetNumber returns the field number of the extension
+func (e *PKExtensionDescriptor) GetNumber() int32 { return e.ProtoDesc().GetNumber() }
+
+// GetType returns the field type of the extension
+func (e *PKExtensionDescriptor) GetType() descriptorpb.FieldDescriptorProto_Type {
+	return e.ProtoDesc().GetType()
+}
+
+// IsRepeated returns whether or not this is a repeated extension
+func (e *PKExtensionDescriptor) IsRepeated() bool {
+	return e.ProtoDesc().GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+}
+
+// GetTypeName returns the fully qualified type name of the extension (e.g. `.pkg.Msg`). Scalar extensions return an
+// empty string
+func (e *PKExtensionDescriptor) GetTypeName() string { return e.ProtoDesc().GetTypeName() }
+
+// GetExtendee returns the fully qualified name of the message being extended (e.g. `.google.protobuf.FieldOptions`)
+func (e *PKExtensionDescriptor) GetExtendee() string { return e.ProtoDesc().GetExtendee() }
+
+// GetExtendedMessage returns the descriptor of the message being extended (returns `nil` if it couldn't be resolved)
+func (e *PKExtensionDescriptor) GetExtendedMessage() *PKDescriptor {
+	if e == nil {
+		return nil
+	}
+
+	return e.ExtendedMessage
+}
 
 // A PKDescriptor describes a message
 type PKDescriptor struct {
@@ -296,29 +812,161 @@ type PKDescriptor struct {
 	Extensions []*PKExtensionDescriptor
 	Fields     []*PKFieldDescriptor
 	Messages   []*PKDescriptor
+	MapEntries []*PKDescriptor
+
+	MessageDescriptor protoreflect.MessageDescriptor
+
+	requestOf  []*PKMethodDescriptor
+	responseOf []*PKMethodDescriptor
 }
 
-func (m *PKDescriptor) ProtoDesc() *descriptorpb.DescriptorProto { return m.desc }
+func (m *PKDescriptor) ProtoDesc() *descriptorpb.DescriptorProto {
+	if m == nil {
+		return nil
+	}
+
+	return m.desc
+}
 
 func (m *PKDescriptor) GetName() string { return m.ProtoDesc().GetName() }
 
 // GetComments returns a description of the message
-func (m *PKDescriptor) GetComments() *Comment { return m.Comments }
+func (m *PKDescriptor) GetComments() *Comment {
+	if m == nil {
+		return nil
+	}
+
+	return m.Comments
+}
 
 // GetParent returns the parent descriptor (if any) that defines this descriptor
-func (m *PKDescriptor) GetParent() *PKDescriptor { return m.Parent }
+func (m *PKDescriptor) GetParent() *PKDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.Parent
+}
+
+// EnclosingTypes returns the chain of messages enclosing this message, from the outermost one down to the message itself
+// (e.g. `[Outer, Middle, Inner]` for `Outer.Middle.Inner`). A top-level message returns only itself.
+func (m *PKDescriptor) EnclosingTypes() []*PKDescriptor {
+	types := make([]*PKDescriptor, 0)
+	for t := m; t != nil; t = t.GetParent() {
+		types = append([]*PKDescriptor{t}, types...)
+	}
+
+	return types
+}
 
 // GetEnums returns the nested enumerations within the message
-func (m *PKDescriptor) GetEnums() []*PKEnumDescriptor { return m.Enums }
+func (m *PKDescriptor) GetEnums() []*PKEnumDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.Enums
+}
 
 // GetExtensions returns the message-level extensions defined by this message
-func (m *PKDescriptor) GetExtensions() []*PKExtensionDescriptor { return m.Extensions }
+func (m *PKDescriptor) GetExtensions() []*PKExtensionDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.Extensions
+}
+
+// GetMessages returns the nested messages within the message. Synthetic map entry messages are not included (see
+// `GetMapEntries`)
+func (m *PKDescriptor) GetMessages() []*PKDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.Messages
+}
+
+// GetMapEntries returns the synthetic map entry messages generated for the map fields of the message
+func (m *PKDescriptor) GetMapEntries() []*PKDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.MapEntries
+}
+
+// IsMapEntry returns whether or not this is a synthetic map entry message generated for a map field
+func (m *PKDescriptor) IsMapEntry() bool { return m.ProtoDesc().GetOptions().GetMapEntry() }
+
+// GetKeyField returns the key field (number 1) of a synthetic map entry message (returns `nil` if this isn't a map entry)
+func (m *PKDescriptor) GetKeyField() *PKFieldDescriptor {
+	if !m.IsMapEntry() {
+		return nil
+	}
+
+	return m.GetFieldByNumber(1)
+}
+
+// GetValueField returns the value field (number 2) of a synthetic map entry message (returns `nil` if this isn't a map
+// entry)
+func (m *PKDescriptor) GetValueField() *PKFieldDescriptor {
+	if !m.IsMapEntry() {
+		return nil
+	}
 
-// GetMessages returns the nested messages within the message
-func (m *PKDescriptor) GetMessages() []*PKDescriptor { return m.Messages }
+	return m.GetFieldByNumber(2)
+}
 
 // GetMessageFields returns the message fields
-func (m *PKDescriptor) GetMessageFields() []*PKFieldDescriptor { return m.Fields }
+func (m *PKDescriptor) GetMessageFields() []*PKFieldDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.Fields
+}
+
+// GetMessageDescriptor returns the underlying `protoreflect.MessageDescriptor`
+func (m *PKDescriptor) GetMessageDescriptor() protoreflect.MessageDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.MessageDescriptor
+}
+
+// UsedAsRequestBy returns the methods (across all parsed files) that use this message as their input type
+func (m *PKDescriptor) UsedAsRequestBy() []*PKMethodDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.requestOf
+}
+
+// UsedAsResponseBy returns the methods (across all parsed files) that use this message as their output type
+func (m *PKDescriptor) UsedAsResponseBy() []*PKMethodDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.responseOf
+}
+
+// IsRPCType returns whether or not this message is the input or output type of any method
+func (m *PKDescriptor) IsRPCType() bool {
+	return len(m.UsedAsRequestBy()) > 0 || len(m.UsedAsResponseBy()) > 0
+}
+
+// InSameFile returns whether or not the other message is declared in the same file as this one
+func (m *PKDescriptor) InSameFile(other *PKDescriptor) bool {
+	if m == nil || other == nil {
+		return false
+	}
+
+	return SameFile(m, other)
+}
 
 // GetEnum returns the enum with the specified name. The name can be either simple, or fully qualified (returns `nil` if
 // not found)
@@ -357,25 +1005,325 @@ func (m *PKDescriptor) GetMessageField(name string) *PKFieldDescriptor {
 	return nil
 }
 
+// NumFields returns the number of fields in the message
+func (m *PKDescriptor) NumFields() int { return len(m.GetMessageFields()) }
+
+// HasField returns whether or not the message has a field with the specified name (see `GetMessageField`)
+func (m *PKDescriptor) HasField(name string) bool { return m.GetMessageField(name) != nil }
+
+// HasOneof returns whether or not the message declares a oneof with the specified name. Synthetic oneofs, which wrap
+// proto3 `optional` fields, are included.
+func (m *PKDescriptor) HasOneof(name string) bool {
+	for _, o := range m.ProtoDesc().GetOneofDecl() {
+		if o.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetFieldByJSONName returns the field whose JSON name (see `PKFieldDescriptor.GetJSONName`) is exactly the specified
+// name (returns `nil` if not found). When several fields share the JSON name, a field whose `json_name` is explicitly
+// overridden to that name (i.e. differs from the default lowerCamelCase form of its own name) is preferred over a field
+// for which it's the default, whatever the order of their declaration.
+func (m *PKDescriptor) GetFieldByJSONName(name string) *PKFieldDescriptor {
+	var byDefault *PKFieldDescriptor
+	for _, f := range m.GetMessageFields() {
+		if f.GetJSONName() != name {
+			continue
+		}
+
+		if name != JSONCamelCase(f.GetName()) {
+			return f
+		}
+		if byDefault == nil {
+			byDefault = f
+		}
+	}
+
+	return byDefault
+}
+
+// GetFieldByNumber returns the field with the specified number (returns `nil` if not found)
+func (m *PKDescriptor) GetFieldByNumber(n int32) *PKFieldDescriptor {
+	for _, f := range m.GetMessageFields() {
+		if f.ProtoDesc().GetNumber() == n {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// GetExtensionByNumber returns the extension declared within this message with the specified number (returns `nil` if
+// not found)
+func (m *PKDescriptor) GetExtensionByNumber(n int32) *PKExtensionDescriptor {
+	for _, ext := range m.GetExtensions() {
+		if ext.ProtoDesc().GetNumber() == n {
+			return ext
+		}
+	}
+
+	return nil
+}
+
+// An ExtensionDeclaration describes an extension declared in one of a message's extension ranges (`extensions 100 to 200
+// [declaration = {...}]`)
+type ExtensionDeclaration struct {
+	Number   int32
+	FullName string
+	Type     string
+	Reserved bool
+	Repeated bool
+}
+
+// GetExtensionDeclarations returns the extensions declared in the message's extension ranges, in declaration order
+func (m *PKDescriptor) GetExtensionDeclarations() []*ExtensionDeclaration {
+	decls := make([]*ExtensionDeclaration, 0)
+	for _, r := range m.ProtoDesc().GetExtensionRange() {
+		for _, d := range r.GetOptions().GetDeclaration() {
+			decls = append(decls, &ExtensionDeclaration{
+				Number:   d.GetNumber(),
+				FullName: d.GetFullName(),
+				Type:     d.GetType(),
+				Reserved: d.GetReserved(),
+				Repeated: d.GetRepeated(),
+			})
+		}
+	}
+
+	return decls
+}
+
+// An ExtensionRange describes a range of field numbers reserved for extensions in a message (`extensions 100 to 200`).
+// As in the descriptor, Start is inclusive and End is exclusive.
+type ExtensionRange struct {
+	Start   int32
+	End     int32
+	Options *descriptorpb.ExtensionRangeOptions
+}
+
+// GetStart returns the first field number of the range
+func (r *ExtensionRange) GetStart() int32 {
+	if r == nil {
+		return 0
+	}
+
+	return r.Start
+}
+
+// GetEnd returns the field number following the last one of the range
+func (r *ExtensionRange) GetEnd() int32 {
+	if r == nil {
+		return 0
+	}
+
+	return r.End
+}
+
+// GetOptions returns the options of the range (returns `nil` if the range has none)
+func (r *ExtensionRange) GetOptions() *descriptorpb.ExtensionRangeOptions {
+	if r == nil {
+		return nil
+	}
+
+	return r.Options
+}
+
+// Contains returns whether or not the field number is within the range
+func (r *ExtensionRange) Contains(n int32) bool { return n >= r.GetStart() && n < r.GetEnd() }
+
+// GetExtensionRanges returns the extension ranges of the message, in declaration order. For `extensions 100 to max`, the
+// end of the range is one past the maximum field number (536870911).
+func (m *PKDescriptor) GetExtensionRanges() []*ExtensionRange {
+	ranges := make([]*ExtensionRange, len(m.ProtoDesc().GetExtensionRange()))
+	for i, r := range m.ProtoDesc().GetExtensionRange() {
+		ranges[i] = &ExtensionRange{
+			Start:   r.GetStart(),
+			End:     r.GetEnd(),
+			Options: r.GetOptions(),
+		}
+	}
+
+	return ranges
+}
+
+// IsInExtensionRange returns whether or not the field number is within one of the message's extension ranges
+func (m *PKDescriptor) IsInExtensionRange(n int32) bool {
+	for _, r := range m.GetExtensionRanges() {
+		if r.Contains(n) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // A PKFieldDescriptor describes a message field
 type PKFieldDescriptor struct {
 	common
-	desc     *descriptorpb.FieldDescriptorProto
-	Comments *Comment
-	Message  *PKDescriptor
+	desc        *descriptorpb.FieldDescriptorProto
+	Comments    *Comment
+	Message     *PKDescriptor
+	MessageType *PKDescriptor
+	EnumType    *PKEnumDescriptor
+
+	FieldDescriptor protoreflect.FieldDescriptor
 }
 
 // ProtoDesc returns the underlying `desc`
-func (mf *PKFieldDescriptor) ProtoDesc() *descriptorpb.FieldDescriptorProto { return mf.desc }
+func (mf *PKFieldDescriptor) ProtoDesc() *descriptorpb.FieldDescriptorProto {
+	if mf == nil {
+		return nil
+	}
+
+	return mf.desc
+}
 
 // GetName returns the name of the field
 func (mf *PKFieldDescriptor) GetName() string { return mf.ProtoDesc().GetName() }
 
 // GetComments returns a description of the field
-func (mf *PKFieldDescriptor) GetComments() *Comment { return mf.Comments }
+func (mf *PKFieldDescriptor) GetComments() *Comment {
+	if mf == nil {
+		return nil
+	}
+
+	return mf.Comments
+}
 
 // GetMessage returns the descriptor that defines this field
-func (mf *PKFieldDescriptor) GetMessage() *PKDescriptor { return mf.Message }
+func (mf *PKFieldDescriptor) GetMessage() *PKDescriptor {
+	if mf == nil {
+		return nil
+	}
+
+	return mf.Message
+}
+
+// GetMessageType returns the message type of the field (returns `nil` for non-message fields or unresolved types)
+func (mf *PKFieldDescriptor) GetMessageType() *PKDescriptor {
+	if mf == nil {
+		return nil
+	}
+
+	return mf.MessageType
+}
+
+// GetEnumType returns the enum type of the field (returns `nil` for non-enum fields or unresolved types)
+func (mf *PKFieldDescriptor) GetEnumType() *PKEnumDescriptor {
+	if mf == nil {
+		return nil
+	}
+
+	return mf.EnumType
+}
+
+// GetFieldDescriptor returns the underlying `protoreflect.FieldDescriptor`
+func (mf *PKFieldDescriptor) GetFieldDescriptor() protoreflect.FieldDescriptor {
+	if mf == nil {
+		return nil
+	}
+
+	return mf.FieldDescriptor
+}
+
+// HasOptionalKeyword returns whether or not the field was declared with the `optional` keyword: proto2 optional fields
+// (other than oneof members and map entry fields, which are implicitly optional) and proto3 `optional` fields. Unlike
+// `HasExplicitPresence`, it's false for message fields declared without the keyword and for all fields under editions.
+func (mf *PKFieldDescriptor) HasOptionalKeyword() bool {
+	switch {
+	case mf.GetFile().IsProto3():
+		return mf.ProtoDesc().GetProto3Optional()
+	case mf.GetFile().IsEditions():
+		return false
+	default:
+		return mf.ProtoDesc().GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL && !mf.InRealOneof() &&
+			!mf.GetMessage().IsMapEntry()
+	}
+}
+
+// InRealOneof returns whether or not the field is part of a oneof declared in the proto file. Proto3 `optional` fields
+// belong to a synthetic oneof, which only tracks their presence, so `false` is returned for them.
+func (mf *PKFieldDescriptor) InRealOneof() bool {
+	_, inOneof := mf.GetOneofIndex()
+	return inOneof && !mf.ProtoDesc().GetProto3Optional()
+}
+
+// GetOneofIndex returns the raw index of the field's oneof in the message's `oneof_decl`, and whether the field belongs
+// to a oneof at all. The index includes synthetic oneofs, so it's also set for proto3 `optional` fields (see
+// `InRealOneof`).
+func (mf *PKFieldDescriptor) GetOneofIndex() (int32, bool) {
+	if mf.ProtoDesc() == nil || mf.ProtoDesc().OneofIndex == nil {
+		return 0, false
+	}
+
+	return mf.ProtoDesc().GetOneofIndex(), true
+}
+
+// IsGroup returns whether or not this is a (proto2) group field. The group's message is available via `GetMessageType`
+func (mf *PKFieldDescriptor) IsGroup() bool {
+	return mf.ProtoDesc().GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP
+}
+
+// IsMap returns whether or not this is a map field
+func (mf *PKFieldDescriptor) IsMap() bool {
+	return mf.ProtoDesc().GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED &&
+		mf.GetMessageType() != nil && mf.GetMessageType().IsMapEntry()
+}
+
+// GetMapKey returns the key field of a map field's entry message (returns `nil` if this isn't a map field)
+func (mf *PKFieldDescriptor) GetMapKey() *PKFieldDescriptor {
+	if !mf.IsMap() {
+		return nil
+	}
+
+	return mf.GetMessageType().GetKeyField()
+}
+
+// GetMapValue returns the value field of a map field's entry message (returns `nil` if this isn't a map field)
+func (mf *PKFieldDescriptor) GetMapValue() *PKFieldDescriptor {
+	if !mf.IsMap() {
+		return nil
+	}
+
+	return mf.GetMessageType().GetValueField()
+}
+
+// IsMapKey returns whether or not this is the key field (number 1) of a synthetic map entry message
+func (mf *PKFieldDescriptor) IsMapKey() bool {
+	return mf.GetMessage().IsMapEntry() && mf.ProtoDesc().GetNumber() == 1
+}
+
+// IsMapValue returns whether or not this is the value field (number 2) of a synthetic map entry message
+func (mf *PKFieldDescriptor) IsMapValue() bool {
+	return mf.GetMessage().IsMapEntry() && mf.ProtoDesc().GetNumber() == 2
+}
+
+// GetJSONName returns the JSON name of the field, i.e. its `json_name` option if set or else the lowerCamelCase form of its
+// name (see `JSONCamelCase`)
+func (mf *PKFieldDescriptor) GetJSONName() string {
+	if mf.ProtoDesc() != nil && mf.ProtoDesc().JsonName != nil {
+		return mf.ProtoDesc().GetJsonName()
+	}
+
+	return JSONCamelCase(mf.GetName())
+}
+
+// GetTypeName returns the fully qualified type name of the field (e.g. `.pkg.Msg`). Scalar fields return an empty string
+func (mf *PKFieldDescriptor) GetTypeName() string { return mf.ProtoDesc().GetTypeName() }
+
+// GetTypeLongName returns the fully qualified type name of the field without the leading dot (e.g. `pkg.Msg`)
+func (mf *PKFieldDescriptor) GetTypeLongName() string {
+	return strings.TrimPrefix(mf.GetTypeName(), ".")
+}
+
+// GetTypeShortName returns the last component of the field's type name (e.g. `Msg`)
+func (mf *PKFieldDescriptor) GetTypeShortName() string {
+	name := mf.GetTypeName()
+	return name[strings.LastIndex(name, ".")+1:]
+}
 
 // A PKServiceDescriptor describes a service
 type PKServiceDescriptor struct {
@@ -387,16 +1335,34 @@ type PKServiceDescriptor struct {
 }
 
 // ProtoDesc returns the underlying `desc`
-func (s *PKServiceDescriptor) ProtoDesc() *descriptorpb.ServiceDescriptorProto { return s.desc }
+func (s *PKServiceDescriptor) ProtoDesc() *descriptorpb.ServiceDescriptorProto {
+	if s == nil {
+		return nil
+	}
+
+	return s.desc
+}
 
 // GetName returns the name of the service
 func (s *PKServiceDescriptor) GetName() string { return s.ProtoDesc().GetName() }
 
 // GetComments returns a description of the service
-func (s *PKServiceDescriptor) GetComments() *Comment { return s.Comments }
+func (s *PKServiceDescriptor) GetComments() *Comment {
+	if s == nil {
+		return nil
+	}
+
+	return s.Comments
+}
 
 // GetMethods returns the methods for the service
-func (s *PKServiceDescriptor) GetMethods() []*PKMethodDescriptor { return s.Methods }
+func (s *PKServiceDescriptor) GetMethods() []*PKMethodDescriptor {
+	if s == nil {
+		return nil
+	}
+
+	return s.Methods
+}
 
 // GetNamedMethod returns the method with the specified name (if found)
 func (s *PKServiceDescriptor) GetNamedMethod(name string) *PKMethodDescriptor {
@@ -409,6 +1375,37 @@ func (s *PKServiceDescriptor) GetNamedMethod(name string) *PKMethodDescriptor {
 	return nil
 }
 
+// GetUnaryMethods returns the methods of the service that stream neither their input nor their output
+func (s *PKServiceDescriptor) GetUnaryMethods() []*PKMethodDescriptor {
+	return s.filterMethods((*PKMethodDescriptor).IsUnary)
+}
+
+// GetServerStreamingMethods returns the methods of the service that only stream their output
+func (s *PKServiceDescriptor) GetServerStreamingMethods() []*PKMethodDescriptor {
+	return s.filterMethods((*PKMethodDescriptor).IsServerStreamingOnly)
+}
+
+// GetClientStreamingMethods returns the methods of the service that only stream their input
+func (s *PKServiceDescriptor) GetClientStreamingMethods() []*PKMethodDescriptor {
+	return s.filterMethods((*PKMethodDescriptor).IsClientStreamingOnly)
+}
+
+// GetBidiStreamingMethods returns the methods of the service that stream both their input and their output
+func (s *PKServiceDescriptor) GetBidiStreamingMethods() []*PKMethodDescriptor {
+	return s.filterMethods((*PKMethodDescriptor).IsBidiStreaming)
+}
+
+func (s *PKServiceDescriptor) filterMethods(match func(*PKMethodDescriptor) bool) []*PKMethodDescriptor {
+	methods := make([]*PKMethodDescriptor, 0)
+	for _, m := range s.GetMethods() {
+		if match(m) {
+			methods = append(methods, m)
+		}
+	}
+
+	return methods
+}
+
 // A PKMethodDescriptor describes a method in a service
 type PKMethodDescriptor struct {
 	common
@@ -421,24 +1418,130 @@ type PKMethodDescriptor struct {
 }
 
 // ProtoDesc returns the underlying `desc`
-func (m *PKMethodDescriptor) ProtoDesc() *descriptorpb.MethodDescriptorProto { return m.desc }
+func (m *PKMethodDescriptor) ProtoDesc() *descriptorpb.MethodDescriptorProto {
+	if m == nil {
+		return nil
+	}
+
+	return m.desc
+}
 
 // GetName returns the name of the method
 func (m *PKMethodDescriptor) GetName() string { return m.ProtoDesc().GetName() }
 
 // GetInputType returns the input message type
-func (m *PKMethodDescriptor) GetInputType() *PKDescriptor { return m.InputType }
+func (m *PKMethodDescriptor) GetInputType() *PKDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.InputType
+}
 
 // GetOutputType returns the output message type
-func (m *PKMethodDescriptor) GetOutputType() *PKDescriptor { return m.OutputType }
+func (m *PKMethodDescriptor) GetOutputType() *PKDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.OutputType
+}
+
+// GetInputTypeName returns the fully-qualified name of the input type as declared (e.g. `.pkg.Request`), which is
+// available even when the type couldn't be resolved (i.e. `GetInputType` returns `nil`)
+func (m *PKMethodDescriptor) GetInputTypeName() string { return m.ProtoDesc().GetInputType() }
+
+// GetOutputTypeName returns the fully-qualified name of the output type as declared (e.g. `.pkg.Response`), which is
+// available even when the type couldn't be resolved (i.e. `GetOutputType` returns `nil`)
+func (m *PKMethodDescriptor) GetOutputTypeName() string { return m.ProtoDesc().GetOutputType() }
 
 // GetComments returns a description of the method
-func (m *PKMethodDescriptor) GetComments() *Comment { return m.Comments }
+func (m *PKMethodDescriptor) GetComments() *Comment {
+	if m == nil {
+		return nil
+	}
+
+	return m.Comments
+}
 
 // GetService returns the service descriptor that defines this method
-func (m *PKMethodDescriptor) GetService() *PKServiceDescriptor { return m.Service }
+func (m *PKMethodDescriptor) GetService() *PKServiceDescriptor {
+	if m == nil {
+		return nil
+	}
+
+	return m.Service
+}
+
+// IsClientStreaming returns whether or not the client streams the input messages
+func (m *PKMethodDescriptor) IsClientStreaming() bool { return m.ProtoDesc().GetClientStreaming() }
+
+// IsServerStreaming returns whether or not the server streams the output messages
+func (m *PKMethodDescriptor) IsServerStreaming() bool { return m.ProtoDesc().GetServerStreaming() }
+
+// IsUnary returns whether or not the method streams neither its input nor its output
+func (m *PKMethodDescriptor) IsUnary() bool { return !m.IsClientStreaming() && !m.IsServerStreaming() }
+
+// IsClientStreamingOnly returns whether or not the method streams its input but not its output
+func (m *PKMethodDescriptor) IsClientStreamingOnly() bool {
+	return m.IsClientStreaming() && !m.IsServerStreaming()
+}
+
+// IsServerStreamingOnly returns whether or not the method streams its output but not its input
+func (m *PKMethodDescriptor) IsServerStreamingOnly() bool {
+	return !m.IsClientStreaming() && m.IsServerStreaming()
+}
+
+// IsBidiStreaming returns whether or not the method streams both its input and its output
+func (m *PKMethodDescriptor) IsBidiStreaming() bool {
+	return m.IsClientStreaming() && m.IsServerStreaming()
+}
+
+// Signature returns the signature of the method using the short names of its types, e.g.
+// `rpc GetUser(GetUserRequest) returns (stream GetUserResponse)`
+func (m *PKMethodDescriptor) Signature() string { return m.signature(false) }
+
+// QualifiedSignature returns the signature of the method using the fully-qualified names of its types, e.g.
+// `rpc GetUser(pkg.GetUserRequest) returns (stream pkg.GetUserResponse)`
+func (m *PKMethodDescriptor) QualifiedSignature() string { return m.signature(true) }
+
+func (m *PKMethodDescriptor) signature(qualified bool) string {
+	typeName := func(resolved *PKDescriptor, name string) string {
+		if resolved != nil {
+			name = resolved.GetFullName()
+		}
+		name = strings.TrimPrefix(name, ".")
+		if !qualified {
+			name = name[strings.LastIndex(name, ".")+1:]
+		}
+
+		return name
+	}
+
+	return fmt.Sprintf("rpc %s(%s) returns (%s)", m.GetName(),
+		streamType(m.IsClientStreaming(), typeName(m.GetInputType(), m.GetInputTypeName())),
+		streamType(m.IsServerStreaming(), typeName(m.GetOutputType(), m.GetOutputTypeName())))
+}
+
+// GetInputFieldPaths returns the dot-separated paths of the input message's fields with nested messages flattened (see
+// `FlattenFields`)
+func (m *PKMethodDescriptor) GetInputFieldPaths() []string { return FlattenFields(m.GetInputType(), 0) }
+
+// GetIdempotencyLevel returns the `idempotency_level` option of the method
+func (m *PKMethodDescriptor) GetIdempotencyLevel() descriptorpb.MethodOptions_IdempotencyLevel {
+	return m.ProtoDesc().GetOptions().GetIdempotencyLevel()
+}
+
+// IsIdempotent returns whether or not the method is marked as idempotent (`IDEMPOTENT` or `NO_SIDE_EFFECTS`)
+func (m *PKMethodDescriptor) IsIdempotent() bool {
+	return m.GetIdempotencyLevel() != descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN
+}
 
 // GetMethodDescriptor returns the underlying `protoreflect.MethodDescriptor`
 func (m *PKMethodDescriptor) GetMethodDescriptor() protoreflect.MethodDescriptor {
+	if m == nil {
+		return nil
+	}
+
 	return m.MethodDescriptor
 }
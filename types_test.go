@@ -0,0 +1,1089 @@
+package protokit
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestGetImportPaths(t *testing.T) {
+	files := parseFixture(t, "shop")
+
+	tests := []struct {
+		file string
+		want []ImportPath
+	}{
+		{
+			file: "shop/shop.proto",
+			want: []ImportPath{
+				{Path: "common/reexport.proto"},
+				{Path: "google/protobuf/timestamp.proto"},
+				{Path: "extra/weak.proto", IsWeak: true},
+				{Path: "extra/absent.proto", IsWeak: true},
+			},
+		},
+		{
+			file: "common/reexport.proto",
+			want: []ImportPath{{Path: "common/money.proto", IsPublic: true}},
+		},
+		{
+			file: "common/money.proto",
+			want: []ImportPath{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := files[tt.file].GetImportPaths(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetImportPaths() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldTypeNames(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	tests := []struct {
+		field                     string
+		name, longName, shortName string
+	}{
+		{field: "price", name: ".common.Money", longName: "common.Money", shortName: "Money"},
+		{field: "color", name: ".shop.Color", longName: "shop.Color", shortName: "Color"},
+		{field: "detail", name: ".shop.Item.Detail", longName: "shop.Item.Detail", shortName: "Detail"},
+		{field: "item_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			f := item.GetMessageField(tt.field)
+			if got := f.GetTypeName(); got != tt.name {
+				t.Errorf("GetTypeName() = %q, want %q", got, tt.name)
+			}
+			if got := f.GetTypeLongName(); got != tt.longName {
+				t.Errorf("GetTypeLongName() = %q, want %q", got, tt.longName)
+			}
+			if got := f.GetTypeShortName(); got != tt.shortName {
+				t.Errorf("GetTypeShortName() = %q, want %q", got, tt.shortName)
+			}
+		})
+	}
+}
+
+func TestGetFileOption(t *testing.T) {
+	f := parseFixtureFile(t, "options", "user/user.proto")
+
+	if got, ok := f.GetFileOption(".opts.policy"); !ok || got != "strict" {
+		t.Errorf(`GetFileOption(".opts.policy") = %v, %v, want "strict", true`, got, ok)
+	}
+	if got, ok := f.GetFileOptionString("opts.policy"); !ok || got != "strict" {
+		t.Errorf(`GetFileOptionString("opts.policy") = %q, %v, want "strict", true`, got, ok)
+	}
+	if got, ok := f.GetFileOptionBool("opts.strict"); !ok || !got {
+		t.Errorf(`GetFileOptionBool("opts.strict") = %v, %v, want true, true`, got, ok)
+	}
+	if got, ok := f.GetFileOptionInt("opts.level"); !ok || got != 3 {
+		t.Errorf(`GetFileOptionInt("opts.level") = %v, %v, want 3, true`, got, ok)
+	}
+	if got, ok := f.GetFileOptionFloat("opts.ratio"); !ok || got != 0.5 {
+		t.Errorf(`GetFileOptionFloat("opts.ratio") = %v, %v, want 0.5, true`, got, ok)
+	}
+
+	// mismatched types and unset options
+	if _, ok := f.GetFileOptionBool("opts.policy"); ok {
+		t.Error(`GetFileOptionBool("opts.policy") reported a string option as a bool`)
+	}
+	if _, ok := f.GetFileOption("opts.missing"); ok {
+		t.Error(`GetFileOption("opts.missing") reported an unset option`)
+	}
+}
+
+func TestGetValueByNumber(t *testing.T) {
+	color := parseFixtureFile(t, "shop", "shop/shop.proto").GetEnum("Color")
+	status := parseFixtureFile(t, "legacy", "legacy/legacy.proto").GetEnum("Status")
+
+	tests := []struct {
+		enum   *PKEnumDescriptor
+		number int32
+		want   string
+	}{
+		{enum: color, number: 0, want: "COLOR_UNSPECIFIED"},
+		{enum: color, number: 1, want: "COLOR_RED"},
+		{enum: color, number: 2},
+		// ACTIVE and ENABLED are aliases
+		{enum: status, number: 1, want: "ACTIVE"},
+		{enum: status, number: 2, want: "INACTIVE"},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if v := tt.enum.GetValueByNumber(tt.number); v != nil {
+			got = v.GetName()
+		}
+		if got != tt.want {
+			t.Errorf("%s.GetValueByNumber(%d) = %q, want %q", tt.enum.GetName(), tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestIsRPCType(t *testing.T) {
+	files := parseFixture(t, "shop")
+	shop := files["shop/shop.proto"]
+
+	methodNames := func(methods []*PKMethodDescriptor) []string {
+		names := make([]string, 0, len(methods))
+		for _, m := range methods {
+			names = append(names, m.GetName())
+		}
+		return names
+	}
+
+	tests := []struct {
+		msg                 *PKDescriptor
+		rpc                 bool
+		requestOf, response []string
+	}{
+		{
+			msg:       shop.GetMessage("GetItemRequest"),
+			rpc:       true,
+			requestOf: []string{"GetItem", "ListItems"},
+			response:  []string{"Upload"},
+		},
+		{
+			msg:       shop.GetMessage("Item"),
+			rpc:       true,
+			requestOf: []string{"Upload", "Chat"},
+			response:  []string{"GetItem", "Chat"},
+		},
+		{msg: shop.GetMessage("ListItemsResponse"), rpc: true, requestOf: []string{}, response: []string{"ListItems"}},
+		{msg: shop.GetMessage("GetItemRequest").GetMessage("Filter"), requestOf: []string{}, response: []string{}},
+		{msg: files["common/money.proto"].GetMessage("Money"), requestOf: []string{}, response: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg.GetLongName(), func(t *testing.T) {
+			if got := tt.msg.IsRPCType(); got != tt.rpc {
+				t.Errorf("IsRPCType() = %v, want %v", got, tt.rpc)
+			}
+			if got := methodNames(tt.msg.UsedAsRequestBy()); !reflect.DeepEqual(got, tt.requestOf) {
+				t.Errorf("UsedAsRequestBy() = %q, want %q", got, tt.requestOf)
+			}
+			if got := methodNames(tt.msg.UsedAsResponseBy()); !reflect.DeepEqual(got, tt.response) {
+				t.Errorf("UsedAsResponseBy() = %q, want %q", got, tt.response)
+			}
+		})
+	}
+}
+
+func TestEnumValueNumberAndDeprecation(t *testing.T) {
+	color := parseFixtureFile(t, "shop", "shop/shop.proto").GetEnum("Color")
+
+	tests := []struct {
+		name       string
+		number     int32
+		deprecated bool
+	}{
+		{name: "COLOR_UNSPECIFIED", number: 0},
+		{name: "COLOR_RED", number: 1, deprecated: true},
+	}
+
+	for _, tt := range tests {
+		v := color.GetNamedValue(tt.name)
+		if got := v.GetNumber(); got != tt.number {
+			t.Errorf("%s.GetNumber() = %d, want %d", tt.name, got, tt.number)
+		}
+		if got := v.IsDeprecated(); got != tt.deprecated {
+			t.Errorf("%s.IsDeprecated() = %v, want %v", tt.name, got, tt.deprecated)
+		}
+	}
+}
+
+func TestGetExtendedMessage(t *testing.T) {
+	opts := parseFixture(t, "options")
+	legacy := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+
+	tests := []struct {
+		ext      *PKExtensionDescriptor
+		extendee string
+		message  *PKDescriptor
+	}{
+		{
+			ext:      opts["opts/options.proto"].GetExtensions()[7],
+			extendee: ".google.protobuf.FieldOptions",
+			message:  opts["google/protobuf/descriptor.proto"].GetMessage("FieldOptions"),
+		},
+		{
+			ext:      legacy.GetExtensions()[0],
+			extendee: ".legacy.Order",
+			message:  legacy.GetMessage("Order"),
+		},
+		{
+			ext:      legacy.GetMessage("Order").GetExtensions()[0],
+			extendee: ".legacy.Order",
+			message:  legacy.GetMessage("Order"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ext.GetName(), func(t *testing.T) {
+			if got := tt.ext.GetExtendee(); got != tt.extendee {
+				t.Errorf("GetExtendee() = %q, want %q", got, tt.extendee)
+			}
+			if tt.message == nil {
+				t.Fatal("extended message missing from the fixture")
+			}
+			if got := tt.ext.GetExtendedMessage(); got != tt.message {
+				t.Errorf("GetExtendedMessage() = %v, want %v", got.GetFullName(), tt.message.GetFullName())
+			}
+		})
+	}
+}
+
+func TestMapEntriesFiltered(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	names := func(msgs []*PKDescriptor) []string {
+		out := make([]string, 0, len(msgs))
+		for _, m := range msgs {
+			out = append(out, m.GetName())
+		}
+		return out
+	}
+
+	if got, want := names(item.GetMessages()), []string{"Detail"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMessages() = %q, want %q", got, want)
+	}
+	if got, want := names(item.GetMapEntries()), []string{"CountsEntry", "PricesEntry"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMapEntries() = %q, want %q", got, want)
+	}
+	if got := item.GetMessage("CountsEntry"); got != nil {
+		t.Errorf(`GetMessage("CountsEntry") = %v, want nil`, got.GetFullName())
+	}
+
+	counts := item.GetMessageField("counts")
+	if got := counts.GetMapKey().GetName(); got != "key" {
+		t.Errorf("counts.GetMapKey() = %q, want key", got)
+	}
+	if got := counts.GetMapValue().GetName(); got != "value" {
+		t.Errorf("counts.GetMapValue() = %q, want value", got)
+	}
+
+	entries := parseFixtureFile(t, "shop", "shop/shop.proto", IncludeMapEntries()).GetMessage("Item").GetMessages()
+	if got, want := names(entries), []string{"CountsEntry", "Detail", "PricesEntry"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMessages() = %q with IncludeMapEntries, want %q", got, want)
+	}
+}
+
+func TestIsMapKeyValue(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	tests := []struct {
+		field      *PKFieldDescriptor
+		key, value bool
+	}{
+		{field: item.GetMapEntries()[0].GetMessageField("key"), key: true},
+		{field: item.GetMapEntries()[0].GetMessageField("value"), value: true},
+		{field: item.GetMapEntries()[1].GetMessageField("key"), key: true},
+		{field: item.GetMapEntries()[1].GetMessageField("value"), value: true},
+		// fields numbered 1 and 2 outside of a map entry
+		{field: item.GetMessageField("item_id")},
+		{field: item.GetMessageField("price")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.GetLongName(), func(t *testing.T) {
+			if got := tt.field.IsMapKey(); got != tt.key {
+				t.Errorf("IsMapKey() = %v, want %v", got, tt.key)
+			}
+			if got := tt.field.IsMapValue(); got != tt.value {
+				t.Errorf("IsMapValue() = %v, want %v", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestGetFieldByNumber(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	tests := []struct {
+		number int32
+		want   string
+	}{
+		{number: 1, want: "item_id"},
+		{number: 6, want: "code"}, // within the `kind` oneof
+		{number: 11, want: "prices"},
+		{number: 12},
+		{number: 0},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if f := item.GetFieldByNumber(tt.number); f != nil {
+			got = f.GetName()
+		}
+		if got != tt.want {
+			t.Errorf("GetFieldByNumber(%d) = %q, want %q", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestGetExtensionByNumber(t *testing.T) {
+	order := parseFixtureFile(t, "legacy", "legacy/legacy.proto").GetMessage("Order")
+
+	if got := order.GetExtensionByNumber(101).GetName(); got != "note" {
+		t.Errorf("GetExtensionByNumber(101) = %q, want note", got)
+	}
+	// a regular field and an extension declared outside of the message
+	for _, n := range []int32{1, 100} {
+		if got := order.GetExtensionByNumber(n); got != nil {
+			t.Errorf("GetExtensionByNumber(%d) = %q, want nil", n, got.GetName())
+		}
+	}
+}
+
+func TestSourcePath(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto")
+	legacy := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+
+	tests := []struct {
+		desc interface{ SourcePath() string }
+		want string
+	}{
+		{desc: shop.GetMessage("Item").GetMessageField("item_id"), want: "shop.Item.item_id (shop/shop.proto:17:3)"},
+		{desc: shop.GetMessage("Item"), want: "shop.Item (shop/shop.proto:15:1)"},
+		{desc: shop.GetService("Shop").GetNamedMethod("GetItem"), want: "shop.Shop.GetItem (shop/shop.proto:78:3)"},
+		// no location recorded for the field
+		{desc: shop.GetMessage("Item").GetMessageField("code"), want: "shop.Item.code (shop/shop.proto)"},
+		// no source code info at all
+		{desc: legacy.GetMessage("Order").GetMessageField("id"), want: "legacy.Order.id (legacy/legacy.proto)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.desc.SourcePath(); got != tt.want {
+			t.Errorf("SourcePath() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestGetExtensionDeclarations(t *testing.T) {
+	f := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+
+	want := []*ExtensionDeclaration{{Number: 1000, FullName: ".legacy.declared_ext", Type: "int32"}}
+	if got := f.GetMessage("Declared").GetExtensionDeclarations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetExtensionDeclarations() = %+v, want %+v", got, want)
+	}
+
+	// extension ranges without declarations
+	if got := f.GetMessage("Order").GetExtensionDeclarations(); len(got) != 0 {
+		t.Errorf("Order.GetExtensionDeclarations() = %+v, want none", got)
+	}
+}
+
+func TestGetIdempotencyLevel(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto").GetService("Shop")
+	idempotent := &PKMethodDescriptor{desc: &descriptorpb.MethodDescriptorProto{
+		Options: &descriptorpb.MethodOptions{IdempotencyLevel: descriptorpb.MethodOptions_IDEMPOTENT.Enum()},
+	}}
+
+	tests := []struct {
+		method     *PKMethodDescriptor
+		level      descriptorpb.MethodOptions_IdempotencyLevel
+		idempotent bool
+	}{
+		{method: shop.GetNamedMethod("GetItem"), level: descriptorpb.MethodOptions_NO_SIDE_EFFECTS, idempotent: true},
+		{method: shop.GetNamedMethod("ListItems"), level: descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN},
+		{method: idempotent, level: descriptorpb.MethodOptions_IDEMPOTENT, idempotent: true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.method.GetIdempotencyLevel(); got != tt.level {
+			t.Errorf("GetIdempotencyLevel() = %v, want %v", got, tt.level)
+		}
+		if got := tt.method.IsIdempotent(); got != tt.idempotent {
+			t.Errorf("IsIdempotent() = %v for %v, want %v", got, tt.level, tt.idempotent)
+		}
+	}
+}
+
+func TestGetIndex(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+	item := f.GetMessage("Item")
+
+	for i, field := range item.GetMessageFields() {
+		if got := field.GetIndex(); got != i {
+			t.Errorf("%s.GetIndex() = %d, want %d", field.GetName(), got, i)
+		}
+	}
+
+	tests := []struct {
+		desc interface {
+			GetLongName() string
+			GetIndex() int
+		}
+		want int
+	}{
+		{desc: f.GetMessage("GetItemRequest"), want: 1},
+		{desc: item.GetMessage("Detail"), want: 1}, // after the CountsEntry map entry
+		{desc: item.GetMapEntries()[1], want: 2},
+		{desc: f.GetEnum("Color").GetNamedValue("COLOR_RED"), want: 1},
+		{desc: f.GetService("Shop"), want: 0},
+		{desc: f.GetService("Shop").GetNamedMethod("Chat"), want: 3},
+	}
+	for _, tt := range tests {
+		if got := tt.desc.GetIndex(); got != tt.want {
+			t.Errorf("%s.GetIndex() = %d, want %d", tt.desc.GetLongName(), got, tt.want)
+		}
+	}
+}
+
+func TestIsMapEntry(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	for _, entry := range item.GetMapEntries() {
+		if !entry.IsMapEntry() {
+			t.Errorf("%s.IsMapEntry() = false, want true", entry.GetName())
+		}
+	}
+	for _, m := range []*PKDescriptor{item, item.GetMessage("Detail")} {
+		if m.IsMapEntry() {
+			t.Errorf("%s.IsMapEntry() = true, want false", m.GetName())
+		}
+	}
+
+	synthetic := &PKDescriptor{desc: &descriptorpb.DescriptorProto{
+		Name:    proto.String("LabelsEntry"),
+		Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+	}}
+	if !synthetic.IsMapEntry() {
+		t.Error("IsMapEntry() = false for a message with the map_entry option, want true")
+	}
+}
+
+func TestGetWeakDependencies(t *testing.T) {
+	files := parseFixture(t, "shop")
+	shop := files["shop/shop.proto"]
+
+	// extra/absent.proto is imported weakly too but isn't in the request
+	weak := shop.GetWeakDependencies()
+	if len(weak) != 1 || weak[0] != files["extra/weak.proto"] {
+		t.Fatalf("GetWeakDependencies() = %v, want [extra/weak.proto]", weak)
+	}
+
+	var deps []string
+	for _, dep := range shop.GetDependencies() {
+		deps = append(deps, dep.GetName())
+	}
+	want := []string{"common/reexport.proto", "google/protobuf/timestamp.proto", "extra/weak.proto"}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("GetDependencies() = %v, want %v", deps, want)
+	}
+
+	if got := files["common/money.proto"].GetWeakDependencies(); len(got) != 0 {
+		t.Errorf("money.proto GetWeakDependencies() = %v, want none", got)
+	}
+}
+
+func TestExtensionFieldAccessors(t *testing.T) {
+	f := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+	exts := make(map[string]*PKExtensionDescriptor)
+	for _, ext := range f.GetExtensions() {
+		exts[ext.GetName()] = ext
+	}
+	for _, ext := range f.GetMessage("Order").GetExtensions() {
+		exts[ext.GetName()] = ext
+	}
+
+	tests := []struct {
+		name     string
+		number   int32
+		typ      descriptorpb.FieldDescriptorProto_Type
+		repeated bool
+		typeName string
+	}{
+		{name: "priority", number: 100, typ: descriptorpb.FieldDescriptorProto_TYPE_INT32},
+		{name: "note", number: 101, typ: descriptorpb.FieldDescriptorProto_TYPE_STRING},
+		{name: "related", number: 102, typ: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated: true,
+			typeName: ".legacy.Order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext, ok := exts[tt.name]
+			if !ok {
+				t.Fatal("extension missing from the fixture")
+			}
+			if got := ext.GetNumber(); got != tt.number {
+				t.Errorf("GetNumber() = %d, want %d", got, tt.number)
+			}
+			if got := ext.GetType(); got != tt.typ {
+				t.Errorf("GetType() = %v, want %v", got, tt.typ)
+			}
+			if got := ext.IsRepeated(); got != tt.repeated {
+				t.Errorf("IsRepeated() = %t, want %t", got, tt.repeated)
+			}
+			if got := ext.GetTypeName(); got != tt.typeName {
+				t.Errorf("GetTypeName() = %q, want %q", got, tt.typeName)
+			}
+		})
+	}
+}
+
+func TestStreamingMethods(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto").GetService("Shop")
+
+	names := func(methods []*PKMethodDescriptor) []string {
+		out := make([]string, 0, len(methods))
+		for _, m := range methods {
+			out = append(out, m.GetName())
+		}
+		return out
+	}
+
+	tests := []struct {
+		filter string
+		got    []*PKMethodDescriptor
+		want   []string
+	}{
+		{filter: "GetUnaryMethods", got: shop.GetUnaryMethods(), want: []string{"GetItem"}},
+		{filter: "GetServerStreamingMethods", got: shop.GetServerStreamingMethods(), want: []string{"ListItems"}},
+		{filter: "GetClientStreamingMethods", got: shop.GetClientStreamingMethods(), want: []string{"Upload"}},
+		{filter: "GetBidiStreamingMethods", got: shop.GetBidiStreamingMethods(), want: []string{"Chat"}},
+	}
+
+	for _, tt := range tests {
+		if got := names(tt.got); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s() = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+
+	// the filters return empty slices rather than nil
+	if got := parseFixtureFile(t, "options", "user/user.proto").GetService("Accounts").GetBidiStreamingMethods(); got == nil ||
+		len(got) != 0 {
+		t.Errorf("Accounts.GetBidiStreamingMethods() = %v, want an empty slice", got)
+	}
+}
+
+func TestGetExtensionsByExtendee(t *testing.T) {
+	names := func(exts []*PKExtensionDescriptor) []string {
+		out := make([]string, 0, len(exts))
+		for _, ext := range exts {
+			out = append(out, ext.GetName())
+		}
+		return out
+	}
+
+	// priority and related are top-level, note is nested within Order
+	byExtendee := parseFixtureFile(t, "legacy", "legacy/legacy.proto").GetExtensionsByExtendee()
+	if len(byExtendee) != 1 {
+		t.Errorf("GetExtensionsByExtendee() = %d extendees, want 1", len(byExtendee))
+	}
+	got := names(byExtendee[".legacy.Order"])
+	sort.Strings(got)
+	if want := []string{"note", "priority", "related"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetExtensionsByExtendee()[.legacy.Order] = %q, want %q", got, want)
+	}
+
+	byExtendee = parseFixtureFile(t, "options", "opts/options.proto").GetExtensionsByExtendee()
+	if got, want := names(byExtendee[".google.protobuf.FileOptions"]), []string{"policy", "strict", "level", "ratio"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetExtensionsByExtendee()[.google.protobuf.FileOptions] = %q, want %q", got, want)
+	}
+	if got := names(byExtendee[".google.protobuf.MethodOptions"]); !reflect.DeepEqual(got, []string{"http_path"}) {
+		t.Errorf("GetExtensionsByExtendee()[.google.protobuf.MethodOptions] = %q, want [http_path]", got)
+	}
+
+	if got := parseFixtureFile(t, "shop", "shop/shop.proto").GetExtensionsByExtendee(); len(got) != 0 {
+		t.Errorf("shop GetExtensionsByExtendee() = %v, want none", got)
+	}
+}
+
+func TestFieldAndOneofHelpers(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto")
+	item := shop.GetMessage("Item")
+
+	if got := item.NumFields(); got != 11 {
+		t.Errorf("Item.NumFields() = %d, want 11", got)
+	}
+	if got := shop.GetMessage("ListItemsResponse").NumFields(); got != 1 {
+		t.Errorf("ListItemsResponse.NumFields() = %d, want 1", got)
+	}
+	if got := parseFixtureFile(t, "options", "user/user.proto").GetMessage("Plain").NumFields(); got != 0 {
+		t.Errorf("Plain.NumFields() = %d, want 0", got)
+	}
+
+	for name, want := range map[string]bool{"item_id": true, "code": true, "itemId": false, "missing": false} {
+		if got := item.HasField(name); got != want {
+			t.Errorf("HasField(%q) = %t, want %t", name, got, want)
+		}
+	}
+	// _limit is the synthetic oneof of the proto3 optional field
+	for name, want := range map[string]bool{"kind": true, "_limit": true, "name": false, "missing": false} {
+		if got := item.HasOneof(name); got != want {
+			t.Errorf("HasOneof(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestGetFieldByJSONName(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+	if got := item.GetFieldByJSONName("itemId"); got == nil || got.GetName() != "item_id" {
+		t.Errorf(`GetFieldByJSONName("itemId") = %v, want item_id`, got)
+	}
+	if got := item.GetFieldByJSONName("item_id"); got != nil {
+		t.Errorf(`GetFieldByJSONName("item_id") = %v, want nil`, got.GetName())
+	}
+
+	// x overrides its JSON name to the default one of foo_bar, which is declared first
+	field := func(name string, number int32, jsonName string) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			JsonName: proto.String(jsonName),
+		}
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"json/json.proto"},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("json/json.proto"),
+			Package: proto.String("json"),
+			Syntax:  proto.String("proto2"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name:  proto.String("Msg"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("foo_bar", 1, "fooBar"), field("x", 2, "fooBar")},
+			}},
+		}},
+	}
+	files, err := ParseCodeGenRequestAllFiles(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := files[0].GetMessage("Msg")
+	if got := msg.GetFieldByJSONName("fooBar"); got == nil || got.GetName() != "x" {
+		t.Errorf(`GetFieldByJSONName("fooBar") = %v, want the overriding field x`, got)
+	}
+	if got := msg.GetFieldByJSONName("x"); got != nil {
+		t.Errorf(`GetFieldByJSONName("x") = %v, want nil as x's JSON name is overridden`, got.GetName())
+	}
+}
+
+func TestInRealOneof(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	tests := []struct {
+		field string
+		want  bool
+	}{
+		{field: "name", want: true},
+		{field: "code", want: true},
+		// proto3 optional, in the synthetic _limit oneof
+		{field: "limit"},
+		{field: "item_id"},
+	}
+
+	for _, tt := range tests {
+		if got := item.GetMessageField(tt.field).InRealOneof(); got != tt.want {
+			t.Errorf("%s.InRealOneof() = %t, want %t", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestGetSyntaxEnum(t *testing.T) {
+	tests := []struct {
+		syntax string
+		want   Syntax
+		str    string
+	}{
+		{syntax: "", want: SyntaxProto2, str: "proto2"},
+		{syntax: "proto2", want: SyntaxProto2, str: "proto2"},
+		{syntax: "proto3", want: SyntaxProto3, str: "proto3"},
+		{syntax: "editions", want: SyntaxEditions, str: "editions"},
+		{syntax: "proto4", want: SyntaxUnknown, str: "unknown"},
+	}
+
+	for _, tt := range tests {
+		f := &PKFileDescriptor{desc: &descriptorpb.FileDescriptorProto{Syntax: proto.String(tt.syntax)}}
+		got := f.GetSyntaxEnum()
+		if got != tt.want {
+			t.Errorf("GetSyntaxEnum() = %v for %q, want %v", got, tt.syntax, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("String() = %q for %q, want %q", got.String(), tt.syntax, tt.str)
+		}
+	}
+
+	// as parsed
+	files := parseSyntaxFixtures(t)
+	for name, want := range map[string]Syntax{"legacy": SyntaxProto2, "shop": SyntaxProto3, "editions": SyntaxEditions} {
+		if got := files[name].GetSyntaxEnum(); got != want {
+			t.Errorf("%s: GetSyntaxEnum() = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto")
+	legacy := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+	item := shop.GetMessage("Item")
+
+	tests := []struct {
+		desc Descriptor
+		want string
+	}{
+		{desc: item, want: "4.0"},
+		{desc: item.GetMessageField("price"), want: "4.0.2.1"},
+		{desc: item.GetMessage("Detail"), want: "4.0.3.1"},
+		{desc: item.GetMessage("Detail").GetMessageField("note"), want: "4.0.3.1.2.1"},
+		{desc: shop.GetEnum("Color"), want: "5.0"},
+		{desc: shop.GetEnum("Color").GetNamedValue("COLOR_RED"), want: "5.0.2.1"},
+		{desc: shop.GetService("Shop"), want: "6.0"},
+		{desc: shop.GetService("Shop").GetNamedMethod("Upload"), want: "6.0.2.2"},
+		{desc: legacy.GetExtensions()[0], want: "7.0"},
+		{desc: legacy.GetMessage("Order").GetExtensions()[0], want: "4.0.6.0"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.desc.GetPath(); got != tt.want {
+			t.Errorf("%s.GetPath() = %q, want %q", tt.desc.GetFullName(), got, tt.want)
+		}
+	}
+
+	// the path locates the descriptor's comments
+	if got := shop.GetCommentForPath(item.GetMessageField("item_id").GetPath()).GetLeading(); got != "The identifier." {
+		t.Errorf("comment at item_id's path = %q, want %q", got, "The identifier.")
+	}
+}
+
+func TestGetExtensionRanges(t *testing.T) {
+	f := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+
+	order := f.GetMessage("Order")
+	ranges := order.GetExtensionRanges()
+	if len(ranges) != 1 || ranges[0].GetStart() != 100 || ranges[0].GetEnd() != 536870912 {
+		t.Fatalf("Order.GetExtensionRanges() = %v, want [100, 536870912)", ranges)
+	}
+	for n, want := range map[int32]bool{1: false, 99: false, 100: true, 101: true, 536870911: true, 536870912: false} {
+		if got := order.IsInExtensionRange(n); got != want {
+			t.Errorf("Order.IsInExtensionRange(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	declared := f.GetMessage("Declared").GetExtensionRanges()
+	if len(declared) != 1 || declared[0].GetStart() != 1000 || declared[0].GetEnd() != 2001 {
+		t.Fatalf("Declared.GetExtensionRanges() = %v, want [1000, 2001)", declared)
+	}
+	if !declared[0].Contains(2000) || declared[0].Contains(2001) {
+		t.Errorf("the end of the range is exclusive")
+	}
+	decl := declared[0].GetOptions().GetDeclaration()
+	if len(decl) != 1 || decl[0].GetFullName() != ".legacy.declared_ext" || decl[0].GetNumber() != 1000 {
+		t.Errorf("Declared range declarations = %v", decl)
+	}
+
+	if r := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item"); len(r.GetExtensionRanges()) != 0 || r.IsInExtensionRange(1) {
+		t.Errorf("Item has no extension ranges")
+	}
+
+	var nilRange *ExtensionRange
+	if nilRange.GetStart() != 0 || nilRange.GetEnd() != 0 || nilRange.GetOptions() != nil || nilRange.Contains(0) {
+		t.Errorf("nil ExtensionRange is not empty")
+	}
+}
+
+func TestMethodSignature(t *testing.T) {
+	shop := parseFixtureFile(t, "shop", "shop/shop.proto").GetService("Shop")
+
+	tests := []struct {
+		method    string
+		short     string
+		qualified string
+	}{
+		{
+			method:    "GetItem",
+			short:     "rpc GetItem(GetItemRequest) returns (Item)",
+			qualified: "rpc GetItem(shop.GetItemRequest) returns (shop.Item)",
+		},
+		{
+			method:    "ListItems",
+			short:     "rpc ListItems(GetItemRequest) returns (stream ListItemsResponse)",
+			qualified: "rpc ListItems(shop.GetItemRequest) returns (stream shop.ListItemsResponse)",
+		},
+		{
+			method:    "Upload",
+			short:     "rpc Upload(stream Item) returns (GetItemRequest)",
+			qualified: "rpc Upload(stream shop.Item) returns (shop.GetItemRequest)",
+		},
+		{
+			method:    "Chat",
+			short:     "rpc Chat(stream Item) returns (stream Item)",
+			qualified: "rpc Chat(stream shop.Item) returns (stream shop.Item)",
+		},
+	}
+
+	for _, tt := range tests {
+		m := shop.GetNamedMethod(tt.method)
+		if got := m.Signature(); got != tt.short {
+			t.Errorf("%s.Signature() = %q, want %q", tt.method, got, tt.short)
+		}
+		if got := m.QualifiedSignature(); got != tt.qualified {
+			t.Errorf("%s.QualifiedSignature() = %q, want %q", tt.method, got, tt.qualified)
+		}
+	}
+}
+
+func TestMethodTypeNames(t *testing.T) {
+	var shop *PKServiceDescriptor
+	for _, f := range parseUnresolved(t) {
+		if f.GetName() == "shop/shop.proto" {
+			shop = f.GetService("Shop")
+		}
+	}
+
+	get := shop.GetNamedMethod("GetItem")
+	if get.GetInputTypeName() != ".shop.GetItemRequest" || get.GetOutputTypeName() != ".shop.Item" {
+		t.Errorf("GetItem type names = %q, %q", get.GetInputTypeName(), get.GetOutputTypeName())
+	}
+
+	// the names outlive the unresolved types
+	upload := shop.GetNamedMethod("Upload")
+	if upload.GetInputType() != nil {
+		t.Fatalf("Upload.GetInputType() = %v, want nil", upload.GetInputType().GetFullName())
+	}
+	if got := upload.GetInputTypeName(); got != ".shop.Missing" {
+		t.Errorf("Upload.GetInputTypeName() = %q, want .shop.Missing", got)
+	}
+	if got := upload.GetOutputTypeName(); got != ".shop.GetItemRequest" {
+		t.Errorf("Upload.GetOutputTypeName() = %q, want .shop.GetItemRequest", got)
+	}
+	if got, want := upload.QualifiedSignature(), "rpc Upload(stream shop.Missing) returns (shop.GetItemRequest)"; got != want {
+		t.Errorf("Upload.QualifiedSignature() = %q, want %q", got, want)
+	}
+}
+
+func TestSameFile(t *testing.T) {
+	files := parseFixture(t, "shop")
+	shop := files["shop/shop.proto"]
+	item := shop.GetMessage("Item")
+	money := files["common/money.proto"].GetMessage("Money")
+
+	if !item.InSameFile(shop.GetMessage("GetItemRequest")) {
+		t.Error("Item and GetItemRequest are declared in the same file")
+	}
+	if !item.InSameFile(item.GetMessage("Detail").GetMessage("Note")) {
+		t.Error("Item and Item.Detail.Note are declared in the same file")
+	}
+	if item.InSameFile(money) {
+		t.Error("Item and common.Money are declared in different files")
+	}
+	if item.InSameFile(nil) || (*PKDescriptor)(nil).InSameFile(item) {
+		t.Error("InSameFile() is true for a nil message")
+	}
+
+	// SameFile works across kinds of descriptors
+	if !SameFile(item.GetMessageField("color"), shop.GetEnum("Color")) {
+		t.Error("Item.color and Color are declared in the same file")
+	}
+	if !SameFile(shop.GetService("Shop").GetNamedMethod("Chat"), item) {
+		t.Error("Shop.Chat and Item are declared in the same file")
+	}
+	if SameFile(item.GetMessageField("price"), money.GetMessageField("units")) {
+		t.Error("Item.price and Money.units are declared in different files")
+	}
+	if SameFile((*PKDescriptor)(nil), (*PKEnumDescriptor)(nil)) {
+		t.Error("SameFile() is true for nil descriptors")
+	}
+}
+
+func TestEnclosingTypes(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	names := func(types []*PKDescriptor) []string {
+		out := make([]string, 0, len(types))
+		for _, m := range types {
+			out = append(out, m.GetName())
+		}
+		return out
+	}
+
+	// Item.detail points at Item.Detail, whose note points at Item.Detail.Note
+	note := item.GetMessageField("detail").GetMessageType().GetMessageField("note").GetMessageType()
+	if got, want := names(note.EnclosingTypes()), []string{"Item", "Detail", "Note"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Note.EnclosingTypes() = %q, want %q", got, want)
+	}
+	if got := note.EnclosingTypes(); got[0] != item {
+		t.Errorf("Note.EnclosingTypes()[0] = %p, want the parsed Item %p", got[0], item)
+	}
+	if got, want := names(item.EnclosingTypes()), []string{"Item"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Item.EnclosingTypes() = %q, want %q", got, want)
+	}
+	if got := (*PKDescriptor)(nil).EnclosingTypes(); got == nil || len(got) != 0 {
+		t.Errorf("nil EnclosingTypes() = %v, want an empty slice", got)
+	}
+}
+
+func TestGetAllExtensions(t *testing.T) {
+	f := parseFixtureFile(t, "legacy", "legacy/legacy.proto")
+
+	got := make([]string, 0)
+	for _, ext := range f.GetAllExtensions() {
+		got = append(got, ext.GetFullName())
+	}
+
+	// the top-level extensions come first, then the one nested within Order, even though Order and its extension are
+	// declared before the top-level extensions (extensions are named after their extendee)
+	want := []string{".legacy.Order.priority", ".legacy.Order.related", ".legacy.Order.note"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllExtensions() = %q, want %q", got, want)
+	}
+	if got := len(f.GetExtensions()); got != 2 {
+		t.Errorf("GetExtensions() = %d extensions, want the 2 top-level ones", got)
+	}
+
+	if got := parseFixtureFile(t, "shop", "shop/shop.proto").GetAllExtensions(); got == nil || len(got) != 0 {
+		t.Errorf("GetAllExtensions() = %v, want an empty slice", got)
+	}
+}
+
+func TestReflectDescriptors(t *testing.T) {
+	for name, f := range parseFixture(t, "shop", IncludeMapEntries()) {
+		Walk(f, func(d Descriptor) bool {
+			var got protoreflect.FullName
+			switch d := d.(type) {
+			case *PKDescriptor:
+				got = d.GetMessageDescriptor().FullName()
+			case *PKFieldDescriptor:
+				got = d.GetFieldDescriptor().FullName()
+			default:
+				return true
+			}
+
+			if want := strings.TrimPrefix(d.GetFullName(), "."); string(got) != want {
+				t.Errorf("%s: reflect descriptor of %s = %s", name, want, got)
+			}
+			return true
+		})
+	}
+
+	// the reflect descriptors can build dynamic messages
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+	msg := dynamicpb.NewMessage(item.GetMessageDescriptor())
+	msg.Set(item.GetMessageField("item_id").GetFieldDescriptor(), protoreflect.ValueOfString("42"))
+	if got := msg.Get(msg.Descriptor().Fields().ByNumber(1)).String(); got != "42" {
+		t.Errorf("item_id = %q, want 42", got)
+	}
+	if fd := item.GetMessageField("counts").GetFieldDescriptor(); !fd.IsMap() || fd.MapValue().Kind() != protoreflect.Int32Kind {
+		t.Errorf("counts reflect descriptor = %v, want a map of int32", fd)
+	}
+
+	if (*PKDescriptor)(nil).GetMessageDescriptor() != nil || (*PKFieldDescriptor)(nil).GetFieldDescriptor() != nil {
+		t.Error("nil descriptors return reflect descriptors")
+	}
+}
+
+func TestMapEntryKeyAndValueFields(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+	item := f.GetMessage("Item")
+
+	tests := []struct {
+		entry              *PKDescriptor
+		keyType, valueType descriptorpb.FieldDescriptorProto_Type
+	}{
+		{
+			entry:     item.GetMessageField("counts").GetMessageType(),
+			keyType:   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+			valueType: descriptorpb.FieldDescriptorProto_TYPE_INT32,
+		},
+		{
+			entry:     item.GetMessageField("prices").GetMessageType(),
+			keyType:   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+			valueType: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		},
+	}
+
+	for _, tt := range tests {
+		name := tt.entry.GetName()
+		key, value := tt.entry.GetKeyField(), tt.entry.GetValueField()
+		if key.GetName() != "key" || key.ProtoDesc().GetType() != tt.keyType || !key.IsMapKey() {
+			t.Errorf("%s.GetKeyField() = %s %v", name, key.GetName(), key.ProtoDesc().GetType())
+		}
+		if value.GetName() != "value" || value.ProtoDesc().GetType() != tt.valueType || !value.IsMapValue() {
+			t.Errorf("%s.GetValueField() = %s %v", name, value.GetName(), value.ProtoDesc().GetType())
+		}
+	}
+
+	// the entry fields are the ones returned for the map field
+	counts := item.GetMessageField("counts")
+	if counts.GetMapKey() != counts.GetMessageType().GetKeyField() || counts.GetMapValue() != counts.GetMessageType().GetValueField() {
+		t.Error("GetMapKey()/GetMapValue() differ from the entry's GetKeyField()/GetValueField()")
+	}
+
+	// messages that aren't map entries have neither, even with fields numbered 1 and 2
+	if filter := f.GetMessage("GetItemRequest").GetMessage("Filter"); filter.GetKeyField() != nil || filter.GetValueField() != nil {
+		t.Error("GetItemRequest.Filter has a key or value field")
+	}
+}
+
+func TestGetOneofIndex(t *testing.T) {
+	item := parseFixtureFile(t, "shop", "shop/shop.proto").GetMessage("Item")
+
+	tests := []struct {
+		field string
+		index int32
+		ok    bool
+	}{
+		{field: "item_id"},
+		{field: "name", index: 0, ok: true},
+		{field: "code", index: 0, ok: true},
+		// proto3 optional fields belong to a synthetic oneof, the second one of Item
+		{field: "limit", index: 1, ok: true},
+	}
+
+	for _, tt := range tests {
+		index, ok := item.GetMessageField(tt.field).GetOneofIndex()
+		if index != tt.index || ok != tt.ok {
+			t.Errorf("%s.GetOneofIndex() = %d, %v, want %d, %v", tt.field, index, ok, tt.index, tt.ok)
+		}
+	}
+
+	if name := item.ProtoDesc().GetOneofDecl()[1].GetName(); name != "_limit" {
+		t.Errorf("oneof 1 = %q, want _limit", name)
+	}
+}
+
+func TestEnumValueOptions(t *testing.T) {
+	role := parseFixtureFile(t, "options", "user/user.proto").GetEnum("Role")
+
+	tests := []struct {
+		value      string
+		number     int32
+		label      string
+		deprecated bool
+	}{
+		{value: "ROLE_UNSPECIFIED", number: 0, label: "none"},
+		{value: "ROLE_ADMIN", number: 1, label: "admin", deprecated: true},
+	}
+
+	for _, tt := range tests {
+		v := role.GetNamedValue(tt.value)
+		if got := v.GetNumber(); got != tt.number {
+			t.Errorf("%s.GetNumber() = %d, want %d", tt.value, got, tt.number)
+		}
+		if got := v.IsDeprecated(); got != tt.deprecated {
+			t.Errorf("%s.IsDeprecated() = %v, want %v", tt.value, got, tt.deprecated)
+		}
+
+		// the custom option is captured, while the standard deprecated option isn't an extension
+		want := map[string]interface{}{"opts.label": tt.label}
+		if got := v.GetOptionExtensions(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s.GetOptionExtensions() = %v, want %v", tt.value, got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package protokit
+
+import (
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The GetUninterpretedOptions accessors return the options that protoc couldn't interpret, which is the case for custom
+// options whose extension isn't known. They allow such options to be inspected without registering the extension.
+
+// GetUninterpretedOptions returns the uninterpreted file options
+func (f *PKFileDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return f.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
+
+// GetUninterpretedOptions returns the uninterpreted message options
+func (m *PKDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return m.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
+
+// GetUninterpretedOptions returns the uninterpreted field options
+func (mf *PKFieldDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return mf.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
+
+// GetUninterpretedOptions returns the uninterpreted enum options
+func (e *PKEnumDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return e.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
+
+// GetUninterpretedOptions returns the uninterpreted enum value options
+func (v *PKEnumValueDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return v.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
+
+// GetUninterpretedOptions returns the uninterpreted field options of the extension
+func (e *PKExtensionDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return e.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
+
+// GetUninterpretedOptions returns the uninterpreted service options
+func (s *PKServiceDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return s.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
+
+// GetUninterpretedOptions returns the uninterpreted method options
+func (m *PKMethodDescriptor) GetUninterpretedOptions() []*descriptorpb.UninterpretedOption {
+	return m.ProtoDesc().GetOptions().GetUninterpretedOption()
+}
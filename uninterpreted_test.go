@@ -0,0 +1,29 @@
+package protokit
+
+import (
+	"testing"
+)
+
+func TestGetUninterpretedOptions(t *testing.T) {
+	f := parseFixtureFile(t, "options", "user/user.proto")
+
+	opts := f.GetMessage("Session").GetUninterpretedOptions()
+	if len(opts) != 1 {
+		t.Fatalf("Session.GetUninterpretedOptions() = %d options, want 1", len(opts))
+	}
+	name := opts[0].GetName()
+	if len(name) != 1 || name[0].GetNamePart() != "unknown.opt" || !name[0].GetIsExtension() {
+		t.Errorf("option name = %v, want (unknown.opt)", name)
+	}
+	if got := string(opts[0].GetStringValue()); got != "kept" {
+		t.Errorf("option value = %q, want %q", got, "kept")
+	}
+
+	// interpreted options aren't returned
+	if got := f.GetMessage("Account").GetUninterpretedOptions(); len(got) != 0 {
+		t.Errorf("Account.GetUninterpretedOptions() = %v, want none", got)
+	}
+	if got := f.GetUninterpretedOptions(); len(got) != 0 {
+		t.Errorf("GetUninterpretedOptions() = %v, want none", got)
+	}
+}
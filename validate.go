@@ -0,0 +1,86 @@
+package protokit
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ValidateMethodTypes returns the methods whose input or output type couldn't be resolved, which usually means a file
+// defining the type is missing from the request
+func ValidateMethodTypes(files []*PKFileDescriptor) []*PKMethodDescriptor {
+	unresolved := make([]*PKMethodDescriptor, 0)
+	for _, f := range files {
+		for _, svc := range f.GetServices() {
+			for _, m := range svc.GetMethods() {
+				if m.GetInputType() == nil || m.GetOutputType() == nil {
+					unresolved = append(unresolved, m)
+				}
+			}
+		}
+	}
+
+	return unresolved
+}
+
+// A Warning describes a problem found in a parsed descriptor by `Validate`
+type Warning struct {
+	Descriptor Descriptor
+	Message    string
+}
+
+// String returns the warning prefixed with the source path of the descriptor (see `SourcePath`)
+func (w Warning) String() string { return fmt.Sprintf("%s: %s", w.Descriptor.SourcePath(), w.Message) }
+
+// Validate returns warnings for the references that couldn't be resolved after parsing: the input and output types of
+// methods (see `ValidateMethodTypes`) and the message and enum types of fields, including those of map entries. These
+// usually mean a file defining the type is missing from the request.
+func Validate(files []*PKFileDescriptor) []Warning {
+	warnings := make([]Warning, 0)
+
+	for _, m := range ValidateMethodTypes(files) {
+		if m.GetInputType() == nil {
+			warnings = append(warnings, Warning{m, fmt.Sprintf("unresolved input type %s", m.GetInputTypeName())})
+		}
+		if m.GetOutputType() == nil {
+			warnings = append(warnings, Warning{m, fmt.Sprintf("unresolved output type %s", m.GetOutputTypeName())})
+		}
+	}
+
+	// map entries are visited by Walk too when parsed with `IncludeMapEntries`, so fields are only validated once
+	seen := make(map[*PKFieldDescriptor]bool)
+	validateFields := func(fields []*PKFieldDescriptor) {
+		for _, f := range fields {
+			if seen[f] {
+				continue
+			}
+
+			seen[f] = true
+			switch f.ProtoDesc().GetType() {
+			case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+				if f.GetMessageType() == nil {
+					warnings = append(warnings, Warning{f, fmt.Sprintf("unresolved message type %s", f.GetTypeName())})
+				}
+			case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+				if f.GetEnumType() == nil {
+					warnings = append(warnings, Warning{f, fmt.Sprintf("unresolved enum type %s", f.GetTypeName())})
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		Walk(file, func(d Descriptor) bool {
+			if msg, ok := d.(*PKDescriptor); ok {
+				validateFields(msg.GetMessageFields())
+				for _, entry := range msg.GetMapEntries() {
+					validateFields(entry.GetMessageFields())
+				}
+			}
+
+			return true
+		})
+	}
+
+	return warnings
+}
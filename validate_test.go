@@ -0,0 +1,69 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// parseUnresolved parses the shop fixture, then points `Shop.Upload` at an input type and `Item.color` at an enum that
+// aren't defined anywhere and resolves the types again
+func parseUnresolved(t *testing.T) []*PKFileDescriptor {
+	t.Helper()
+
+	files, err := ParseCodeGenRequestAllFiles(loadFixture(t, "shop", "shop/shop.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if f.GetName() != "shop/shop.proto" {
+			continue
+		}
+
+		f.GetService("Shop").GetNamedMethod("Upload").ProtoDesc().InputType = proto.String(".shop.Missing")
+		f.GetMessage("Item").GetMessageField("color").ProtoDesc().TypeName = proto.String(".shop.MissingEnum")
+		resolveTypes(f)
+	}
+
+	return files
+}
+
+func TestValidateMethodTypes(t *testing.T) {
+	files, err := ParseCodeGenRequestAllFiles(loadFixture(t, "shop", "shop/shop.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ValidateMethodTypes(files); len(got) != 0 {
+		t.Errorf("ValidateMethodTypes() = %d methods, want none", len(got))
+	}
+
+	unresolved := ValidateMethodTypes(parseUnresolved(t))
+	if len(unresolved) != 1 || unresolved[0].GetFullName() != ".shop.Shop.Upload" {
+		t.Fatalf("ValidateMethodTypes() = %v, want .shop.Shop.Upload", unresolved)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	files, err := ParseCodeGenRequestAllFiles(loadFixture(t, "shop", "shop/shop.proto"), IncludeMapEntries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Validate(files); len(got) != 0 {
+		t.Errorf("Validate() = %v, want no warnings", got)
+	}
+
+	got := make([]string, 0)
+	for _, w := range Validate(parseUnresolved(t)) {
+		got = append(got, w.String())
+	}
+
+	want := []string{
+		"shop.Shop.Upload (shop/shop.proto): unresolved input type .shop.Missing",
+		"shop.Item.color (shop/shop.proto): unresolved enum type .shop.MissingEnum",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package protokit
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// A Version describes the version of the compiler that produced a code generator request
+type Version struct {
+	Major  int32
+	Minor  int32
+	Patch  int32
+	Suffix string
+}
+
+// CompilerVersion returns the version of the compiler that produced the request (returns `nil` if the request doesn't
+// specify it)
+func CompilerVersion(req *pluginpb.CodeGeneratorRequest) *Version {
+	v := req.GetCompilerVersion()
+	if v == nil {
+		return nil
+	}
+
+	return &Version{
+		Major:  v.GetMajor(),
+		Minor:  v.GetMinor(),
+		Patch:  v.GetPatch(),
+		Suffix: v.GetSuffix(),
+	}
+}
+
+// GetMajor returns the major version
+func (v *Version) GetMajor() int32 {
+	if v == nil {
+		return 0
+	}
+
+	return v.Major
+}
+
+// GetMinor returns the minor version
+func (v *Version) GetMinor() int32 {
+	if v == nil {
+		return 0
+	}
+
+	return v.Minor
+}
+
+// GetPatch returns the patch version
+func (v *Version) GetPatch() int32 {
+	if v == nil {
+		return 0
+	}
+
+	return v.Patch
+}
+
+// GetSuffix returns the version suffix (e.g. `rc1`), which is empty for releases
+func (v *Version) GetSuffix() string {
+	if v == nil {
+		return ""
+	}
+
+	return v.Suffix
+}
+
+// String returns the version formatted as `major.minor.patch`, followed by `-suffix` when there's a suffix
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.GetMajor(), v.GetMinor(), v.GetPatch())
+	if v.GetSuffix() != "" {
+		s = fmt.Sprintf("%s-%s", s, v.GetSuffix())
+	}
+
+	return s
+}
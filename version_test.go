@@ -0,0 +1,59 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestCompilerVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version *pluginpb.Version
+		want    *Version
+		str     string
+	}{
+		{
+			name:    "release",
+			version: &pluginpb.Version{Major: proto.Int32(25), Minor: proto.Int32(3), Patch: proto.Int32(0)},
+			want:    &Version{Major: 25, Minor: 3},
+			str:     "25.3.0",
+		},
+		{
+			name: "suffix",
+			version: &pluginpb.Version{Major: proto.Int32(26), Minor: proto.Int32(0), Patch: proto.Int32(1),
+				Suffix: proto.String("rc1")},
+			want: &Version{Major: 26, Patch: 1, Suffix: "rc1"},
+			str:  "26.0.1-rc1",
+		},
+		{name: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := loadFixture(t, "shop", "shop/shop.proto")
+			req.CompilerVersion = tt.version
+
+			got := CompilerVersion(req)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("CompilerVersion() = %+v, want %+v", got, tt.want)
+			}
+			if got != nil && got.String() != tt.str {
+				t.Errorf("String() = %q, want %q", got.String(), tt.str)
+			}
+
+			// the version is kept on the parsed files
+			files, err := ParseCodeGenRequestAllFiles(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range files {
+				if got := f.GetCompilerVersion(); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s: GetCompilerVersion() = %+v, want %+v", f.GetName(), got, tt.want)
+				}
+			}
+		})
+	}
+}
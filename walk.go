@@ -0,0 +1,61 @@
+package protokit
+
+// A Descriptor is implemented by every descriptor type defined within a file (messages, fields, enums, enum values,
+// extensions, services and methods)
+type Descriptor interface {
+	GetFile() *PKFileDescriptor
+	GetName() string
+	GetLongName() string
+	GetFullName() string
+	GetComments() *Comment
+	GetOptionExtensions() map[string]interface{}
+	GetPath() string
+	SourcePath() string
+}
+
+// Walk calls fn for every descriptor defined in the file, depth first and in declaration order. When fn returns false,
+// the children of that descriptor are skipped.
+func Walk(file *PKFileDescriptor, fn func(Descriptor) bool) {
+	walkEnums(file.GetEnums(), fn)
+	walkExtensions(file.GetExtensions(), fn)
+	walkMessages(file.GetMessages(), fn)
+
+	for _, svc := range file.GetServices() {
+		if fn(svc) {
+			for _, m := range svc.GetMethods() {
+				fn(m)
+			}
+		}
+	}
+}
+
+func walkEnums(enums []*PKEnumDescriptor, fn func(Descriptor) bool) {
+	for _, e := range enums {
+		if fn(e) {
+			for _, v := range e.GetValues() {
+				fn(v)
+			}
+		}
+	}
+}
+
+func walkExtensions(exts []*PKExtensionDescriptor, fn func(Descriptor) bool) {
+	for _, ext := range exts {
+		fn(ext)
+	}
+}
+
+func walkMessages(msgs []*PKDescriptor, fn func(Descriptor) bool) {
+	for _, m := range msgs {
+		if !fn(m) {
+			continue
+		}
+
+		walkEnums(m.GetEnums(), fn)
+		walkExtensions(m.GetExtensions(), fn)
+		for _, f := range m.GetMessageFields() {
+			fn(f)
+		}
+		walkMessages(m.GetMessages(), fn)
+	}
+}
@@ -0,0 +1,30 @@
+package protokit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	f := parseFixtureFile(t, "shop", "shop/shop.proto")
+
+	// skip the children of Item and the enums to keep the list short
+	var names []string
+	Walk(f, func(d Descriptor) bool {
+		names = append(names, d.GetLongName())
+		_, isEnum := d.(*PKEnumDescriptor)
+		return d.GetLongName() != "Item" && !isEnum
+	})
+
+	want := []string{
+		"Color",
+		"Item",
+		"GetItemRequest", "GetItemRequest.item_id", "GetItemRequest.filter", "GetItemRequest.extra",
+		"GetItemRequest.Filter", "GetItemRequest.Filter.query", "GetItemRequest.Filter.page_size",
+		"ListItemsResponse", "ListItemsResponse.items",
+		"Shop", "Shop.GetItem", "Shop.ListItems", "Shop.Upload", "Shop.Chat",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Walk() visited %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,52 @@
+package protokit
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// wireEncoding returns the wire type of the field along with how its value is encoded within that wire type. Types
+// sharing an encoding (e.g. int32 and int64, or string and bytes) are wire compatible.
+func wireEncoding(t descriptorpb.FieldDescriptorProto_Type) string {
+	switch t {
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32,
+		descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+		descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		return "varint"
+	case descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT64:
+		return "varint/zigzag"
+	case descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+		descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		return "fixed64"
+	case descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+		descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		return "fixed32"
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+		descriptorpb.FieldDescriptorProto_TYPE_MESSAGE:
+		return "bytes"
+	case descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return "group"
+	default:
+		return "unknown"
+	}
+}
+
+// WireSignature returns a string describing how the field is encoded on the wire (e.g. `3:varint` or `4:fixed32:packed`).
+// It only changes when the wire compatibility of the field changes, so renaming a field or switching between compatible
+// types (e.g. int32 and int64) keeps the same signature.
+func (mf *PKFieldDescriptor) WireSignature() string {
+	sig := fmt.Sprintf("%d:%s", mf.ProtoDesc().GetNumber(), wireEncoding(mf.ProtoDesc().GetType()))
+	if mf.IsPacked() {
+		sig += ":packed"
+	}
+
+	return sig
+}
@@ -0,0 +1,101 @@
+package protokit
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestWireSignature(t *testing.T) {
+	// the signature of `Item.code` (an int64 numbered 6) once the change is applied to it
+	signature := func(t *testing.T, change func(*descriptorpb.FieldDescriptorProto)) string {
+		t.Helper()
+
+		req := loadFixture(t, "shop", "shop/shop.proto")
+		for _, pf := range req.GetProtoFile() {
+			if pf.GetName() == "shop/shop.proto" {
+				change(pf.GetMessageType()[0].GetField()[5])
+			}
+		}
+
+		files, err := ParseCodeGenRequestAllFiles(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if f.IsFileToGenerate {
+				return f.GetMessage("Item").GetMessageFields()[5].WireSignature()
+			}
+		}
+
+		t.Fatal("shop/shop.proto not found")
+		return ""
+	}
+
+	want := signature(t, func(*descriptorpb.FieldDescriptorProto) {})
+	if want != "6:varint" {
+		t.Fatalf("WireSignature() = %q, want %q", want, "6:varint")
+	}
+
+	tests := []struct {
+		name       string
+		change     func(*descriptorpb.FieldDescriptorProto)
+		compatible bool
+	}{
+		{
+			name: "rename",
+			change: func(fd *descriptorpb.FieldDescriptorProto) {
+				fd.Name, fd.JsonName = proto.String("id"), proto.String("id")
+			},
+			compatible: true,
+		},
+		{
+			name: "int32",
+			change: func(fd *descriptorpb.FieldDescriptorProto) {
+				fd.Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+			},
+			compatible: true,
+		},
+		{
+			name: "sint64",
+			change: func(fd *descriptorpb.FieldDescriptorProto) {
+				fd.Type = descriptorpb.FieldDescriptorProto_TYPE_SINT64.Enum()
+			},
+		},
+		{
+			name: "fixed64",
+			change: func(fd *descriptorpb.FieldDescriptorProto) {
+				fd.Type = descriptorpb.FieldDescriptorProto_TYPE_FIXED64.Enum()
+			},
+		},
+		{
+			name:   "renumber",
+			change: func(fd *descriptorpb.FieldDescriptorProto) { fd.Number = proto.Int32(16) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := signature(t, tt.change); (got == want) != tt.compatible {
+				t.Errorf("WireSignature() = %q after the change, compatible = %v, want %v", got, got == want, tt.compatible)
+			}
+		})
+	}
+}
+
+func TestWireSignaturePacked(t *testing.T) {
+	order := parseFixtureFile(t, "legacy", "legacy/legacy.proto").GetMessage("Order")
+
+	tests := map[string]string{
+		"codes":        "3:varint",
+		"packed_codes": "4:varint:packed",
+		"shipping":     "5:group",
+		"id":           "1:bytes",
+	}
+	for field, want := range tests {
+		if got := order.GetMessageField(field).WireSignature(); got != want {
+			t.Errorf("%s.WireSignature() = %q, want %q", field, got, want)
+		}
+	}
+}